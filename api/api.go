@@ -23,4 +23,13 @@ import (
 type Config struct {
 	ApiService      client.TapApiServiceApi
 	ApiServiceLogin client.TapApiServiceLoginApi
+	// Target is name of stored target used instead of current one, when not empty
+	Target string
+	// CredentialsOverride, when set, is used instead of credentials read from file
+	CredentialsOverride *Credentials
+	ApplicationUploader ApplicationUploader
+	// EncryptTokens makes SetCredentials store tokens encrypted with passphrase
+	EncryptTokens bool
+	// Passphrase provides passphrase used to encrypt and decrypt stored tokens
+	Passphrase func() (string, error)
 }
@@ -0,0 +1,248 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+// apiVersion is version of API service used by tap-api-service client
+const apiVersion = "v3"
+
+// getAddress builds endpoint URL the way tap-api-service client does, as it does not export it
+func getAddress(address, endpointFormat string, args ...interface{}) string {
+	return fmt.Sprintf("%s/api/%s", address, apiVersion) + fmt.Sprintf(endpointFormat, args...)
+}
+
+// ErrContentLengthRequired is returned when API service does not accept upload of unknown size
+var ErrContentLengthRequired = errors.New("API service requires known content length of uploaded application")
+
+// ApplicationUploader creates application from archive streamed by writeArchive, so that archive
+// does not have to be stored on disk or in memory before upload, or from archive file
+type ApplicationUploader interface {
+	UploadApplication(writeArchive func(io.Writer) error, manifest models.Manifest, timeout time.Duration,
+		progress UploadProgress) (catalogModels.Application, error)
+	UploadApplicationFile(archivePath string, manifest models.Manifest, timeout time.Duration,
+		progress UploadProgress) (catalogModels.Application, error)
+}
+
+// UploadProgress is notified about bytes of application upload sent to API service.
+// Start is called before request is sent, with total size of upload or -1 when it is unknown.
+type UploadProgress interface {
+	Start(total int64)
+	Add(sent int)
+}
+
+// UploadStatusError is returned when API service responds with unexpected status
+type UploadStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *UploadStatusError) Error() string {
+	return fmt.Sprintf("Bad response status: %d, expected status was: %d. Response body: %s",
+		e.StatusCode, http.StatusAccepted, e.Body)
+}
+
+// IsTransientUploadError tells if upload failed because of network or gateway problem,
+// so that it may succeed when repeated. Exceeded upload timeout is not transient.
+func IsTransientUploadError(err error) bool {
+	switch typed := err.(type) {
+	case *UploadStatusError:
+		return typed.StatusCode == http.StatusBadGateway || typed.StatusCode == http.StatusServiceUnavailable ||
+			typed.StatusCode == http.StatusGatewayTimeout
+	case *url.Error:
+		if typed.Timeout() {
+			return false
+		}
+		return IsTransientUploadError(typed.Err)
+	case *net.OpError:
+		return !typed.Timeout()
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// tlsHandshakeTimeoutMessage is message of unexported error returned by http.Transport
+const tlsHandshakeTimeoutMessage = "net/http: TLS handshake timeout"
+
+// IsUploadNotSentError tells if upload failed before request reached API service, because connection
+// could not be established or TLS handshake timed out. Only such upload can be repeated without risk
+// that API service already created the application.
+func IsUploadNotSentError(err error) bool {
+	switch typed := err.(type) {
+	case *url.Error:
+		return IsUploadNotSentError(typed.Err)
+	case *net.OpError:
+		return typed.Op == "dial"
+	}
+	return err != nil && err.Error() == tlsHandshakeTimeoutMessage
+}
+
+type TapApplicationUploader struct {
+	Address   string
+	TokenType string
+	Token     string
+	Client    *http.Client
+}
+
+func (u *TapApplicationUploader) UploadApplication(writeArchive func(io.Writer) error, manifest models.Manifest,
+	timeout time.Duration, progress UploadProgress) (catalogModels.Application, error) {
+
+	bodyReader, bodyWriter := io.Pipe()
+	formWriter := multipart.NewWriter(bodyWriter)
+	writeErrors := make(chan error, 1)
+	go func() {
+		err := writeApplicationForm(formWriter, writeArchive, manifest)
+		bodyWriter.CloseWithError(err)
+		writeErrors <- err
+	}()
+
+	result, err := u.upload(bodyReader, -1, formWriter.FormDataContentType(), timeout, progress)
+	// stops writing archive when request finished before whole body was sent
+	bodyReader.Close()
+	if writeErr := <-writeErrors; err != nil && writeErr != nil && writeErr != io.ErrClosedPipe {
+		return result, writeErr
+	}
+	return result, err
+}
+
+// UploadApplicationFile sends archive file without loading it into memory. Size of the upload
+// is known in advance, so it is accepted also by API service which requires content length.
+func (u *TapApplicationUploader) UploadApplicationFile(archivePath string, manifest models.Manifest,
+	timeout time.Duration, progress UploadProgress) (catalogModels.Application, error) {
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return catalogModels.Application{}, err
+	}
+	defer archive.Close()
+
+	info, err := archive.Stat()
+	if err != nil {
+		return catalogModels.Application{}, err
+	}
+
+	form := &bytes.Buffer{}
+	formWriter := multipart.NewWriter(form)
+	if _, err = formWriter.CreateFormFile("blob", "blob.tar.gz"); err != nil {
+		return catalogModels.Application{}, err
+	}
+	formHead := append([]byte{}, form.Bytes()...)
+	form.Reset()
+	if err = writeManifestPart(formWriter, manifest); err != nil {
+		return catalogModels.Application{}, err
+	}
+	if err = formWriter.Close(); err != nil {
+		return catalogModels.Application{}, err
+	}
+
+	body := io.MultiReader(bytes.NewReader(formHead), archive, form)
+	contentLength := int64(len(formHead)) + info.Size() + int64(form.Len())
+	return u.upload(body, contentLength, formWriter.FormDataContentType(), timeout, progress)
+}
+
+func (u *TapApplicationUploader) upload(body io.Reader, contentLength int64, contentType string,
+	timeout time.Duration, progress UploadProgress) (catalogModels.Application, error) {
+
+	result := catalogModels.Application{}
+
+	if progress != nil {
+		progress.Start(contentLength)
+		body = &progressReader{reader: body, progress: progress}
+	}
+	req, err := http.NewRequest(http.MethodPost, getAddress(u.Address, "/applications"), ioutil.NopCloser(body))
+	if err != nil {
+		return result, err
+	}
+	req.ContentLength = contentLength
+	req.Header.Add("Authorization", commonHttp.GetOAuth2Header(&commonHttp.OAuth2{TokenType: u.TokenType, Token: u.Token}))
+	commonHttp.SetContentType(req, contentType)
+	// lets API service reject upload before archive is sent
+	req.Header.Set("Expect", "100-continue")
+
+	client := *u.Client
+	client.Timeout = timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		err = json.Unmarshal(data, &result)
+		return result, err
+	case http.StatusLengthRequired:
+		return result, ErrContentLengthRequired
+	}
+	return result, &UploadStatusError{StatusCode: resp.StatusCode, Body: string(data)}
+}
+
+func writeApplicationForm(formWriter *multipart.Writer, writeArchive func(io.Writer) error, manifest models.Manifest) error {
+	blobWriter, err := formWriter.CreateFormFile("blob", "blob.tar.gz")
+	if err != nil {
+		return err
+	}
+	if err = writeArchive(blobWriter); err != nil {
+		return err
+	}
+	if err = writeManifestPart(formWriter, manifest); err != nil {
+		return err
+	}
+	return formWriter.Close()
+}
+
+func writeManifestPart(formWriter *multipart.Writer, manifest models.Manifest) error {
+	manifestWriter, err := formWriter.CreateFormFile("manifest", "manifest.json")
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(manifestWriter).Encode(manifest)
+}
+
+type progressReader struct {
+	reader   io.Reader
+	progress UploadProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.progress.Add(n)
+	}
+	return n, err
+}
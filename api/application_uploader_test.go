@@ -0,0 +1,222 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+)
+
+func newTestApplicationUploader(handler http.HandlerFunc) (*TapApplicationUploader, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	client, _ := NewHttpClient(false, CertificateFiles{})
+	return &TapApplicationUploader{Address: server.URL, TokenType: "bearer", Token: "token", Client: client}, server
+}
+
+func writeTestArchive(writer io.Writer) error {
+	_, err := writer.Write([]byte("archive content"))
+	return err
+}
+
+func TestThatUploadApplication_streamsArchiveAndManifest(t *testing.T) {
+	parts := map[string]string{}
+	uploader, server := newTestApplicationUploader(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/applications" {
+			t.Errorf("upload should be sent to applications endpoint, got %s", r.URL.Path)
+		}
+		if r.ContentLength != -1 {
+			t.Errorf("upload should have unknown length, got %d", r.ContentLength)
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for part, err := reader.NextPart(); err == nil; part, err = reader.NextPart() {
+			content, _ := ioutil.ReadAll(part)
+			parts[part.FormName()] = string(content)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"name":"app"}`))
+	})
+	defer server.Close()
+
+	app, err := uploader.UploadApplication(writeTestArchive, models.Manifest{Name: "app"}, 0, nil)
+
+	if err != nil || app.Name != "app" {
+		t.Fatalf("upload returned %v, %v", app, err)
+	}
+	if parts["blob"] != "archive content" {
+		t.Errorf("blob part contains %q", parts["blob"])
+	}
+	if parts["manifest"] == "" {
+		t.Error("manifest part is missing")
+	}
+}
+
+func TestThatUploadApplication_reportsRequiredContentLength(t *testing.T) {
+	archiveWritten := false
+	uploader, server := newTestApplicationUploader(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusLengthRequired)
+	})
+	defer server.Close()
+
+	_, err := uploader.UploadApplication(func(writer io.Writer) error {
+		archiveWritten = true
+		return writeTestArchive(writer)
+	}, models.Manifest{}, 0, nil)
+
+	if err != ErrContentLengthRequired {
+		t.Errorf("upload returned %v, expected ErrContentLengthRequired", err)
+	}
+	if archiveWritten {
+		t.Error("archive should not be sent when API service rejects upload of unknown length")
+	}
+}
+
+func TestThatUploadApplication_returnsArchiveError(t *testing.T) {
+	uploader, server := newTestApplicationUploader(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	defer server.Close()
+	archiveErr := errors.New("cannot read file")
+
+	_, err := uploader.UploadApplication(func(writer io.Writer) error {
+		return archiveErr
+	}, models.Manifest{}, 0, nil)
+
+	if err != archiveErr {
+		t.Errorf("upload returned %v, expected archive error", err)
+	}
+}
+
+type testUploadProgress struct {
+	total int64
+	sent  int64
+}
+
+func (p *testUploadProgress) Start(total int64) {
+	p.total = total
+	p.sent = 0
+}
+
+func (p *testUploadProgress) Add(sent int) {
+	p.sent += int64(sent)
+}
+
+func TestThatUploadApplicationFile_sendsArchiveWithContentLength(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	archivePath := filepath.Join(dir, "blob.tar.gz")
+	if err = ioutil.WriteFile(archivePath, []byte("archive content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var contentLength int64
+	parts := map[string]string{}
+	uploader, server := newTestApplicationUploader(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for part, err := reader.NextPart(); err == nil; part, err = reader.NextPart() {
+			content, _ := ioutil.ReadAll(part)
+			parts[part.FormName()] = string(content)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"name":"app"}`))
+	})
+	defer server.Close()
+	progress := &testUploadProgress{}
+
+	app, err := uploader.UploadApplicationFile(archivePath, models.Manifest{Name: "app"}, 0, progress)
+
+	if err != nil || app.Name != "app" {
+		t.Fatalf("upload returned %v, %v", app, err)
+	}
+	if parts["blob"] != "archive content" || parts["manifest"] == "" {
+		t.Errorf("unexpected form parts: %v", parts)
+	}
+	if contentLength <= 0 || progress.total != contentLength || progress.sent != contentLength {
+		t.Errorf("content length %d, progress reported %d of %d bytes", contentLength, progress.sent, progress.total)
+	}
+}
+
+func TestThatIsTransientUploadError_recognizesNetworkAndGatewayErrors(t *testing.T) {
+	connectionReset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	testCases := []struct {
+		err       error
+		transient bool
+	}{
+		{&url.Error{Op: "Post", URL: "http://api", Err: connectionReset}, true},
+		{&url.Error{Op: "Post", URL: "http://api", Err: io.EOF}, true},
+		{&UploadStatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&UploadStatusError{StatusCode: http.StatusBadRequest}, false},
+		{&url.Error{Op: "Post", URL: "http://api", Err: timeoutError{}}, false},
+		{ErrContentLengthRequired, false},
+		{errors.New("cannot read file"), false},
+	}
+
+	for _, tc := range testCases {
+		if IsTransientUploadError(tc.err) != tc.transient {
+			t.Errorf("IsTransientUploadError(%v) should return %v", tc.err, tc.transient)
+		}
+	}
+}
+
+func TestThatIsUploadNotSentError_recognizesOnlyErrorsBeforeRequestWasSent(t *testing.T) {
+	connectionRefused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	connectionReset := &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	testCases := []struct {
+		err     error
+		notSent bool
+	}{
+		{&url.Error{Op: "Post", URL: "http://api", Err: connectionRefused}, true},
+		{&url.Error{Op: "Post", URL: "http://api", Err: errors.New(tlsHandshakeTimeoutMessage)}, true},
+		{&url.Error{Op: "Post", URL: "http://api", Err: connectionReset}, false},
+		{&url.Error{Op: "Post", URL: "http://api", Err: io.EOF}, false},
+		{&UploadStatusError{StatusCode: http.StatusServiceUnavailable}, false},
+		{nil, false},
+	}
+
+	for _, tc := range testCases {
+		if IsUploadNotSentError(tc.err) != tc.notSent {
+			t.Errorf("IsUploadNotSentError(%v) should return %v", tc.err, tc.notSent)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "Client.Timeout exceeded" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -18,50 +18,299 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 )
 
-//TODO: make credsPath read optionally from environment or options
-var cliConfigDir string = os.Getenv("HOME") + "/.tap-cli"
-var CredsPath string = cliConfigDir + "/credentials.json"
+// CliHomeEnvVar is name of environment variable with directory, in which CLI keeps its files
+const CliHomeEnvVar = "TAP_CLI_HOME"
 
-const PERMISSIONS os.FileMode = 0744
+var cliConfigDir string = getCliConfigDir()
+var CredsPath string = filepath.Join(cliConfigDir, "credentials.json")
 
+// PERMISSIONS of credentials file, it holds tokens so only owner can access it
+const PERMISSIONS os.FileMode = 0600
+const DIR_PERMISSIONS os.FileMode = 0700
+
+// Credentials of single target. ExpiresIn is token lifetime in seconds as returned by login,
+// ExpiresAt is absolute expiry time of token in unix seconds (0 when unknown).
+// Certificate files used at login are stored to secure all subsequent calls.
+// When token is stored encrypted with passphrase, Token is empty in credentials file.
 type Credentials struct {
 	Address           string `json:"address"`
 	Username          string `json:"username"`
 	Token             string `json:"token"`
+	EncryptedToken    string `json:"encrypted-token,omitempty"`
 	TokenType         string `json:"type"`
 	ExpiresIn         int    `json:"expires"`
+	ExpiresAt         int64  `json:"expires-at,omitempty"`
 	SkipSSLValidation bool   `json:"skip-ssl-validation"`
+	CertificateFiles
+}
+
+func (creds Credentials) HasToken() bool {
+	return creds.Token != "" || creds.EncryptedToken != ""
+}
+
+// ExpiryTime returns time at which token expires, zero time when it is unknown
+func (creds Credentials) ExpiryTime() time.Time {
+	if creds.ExpiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(creds.ExpiresAt, 0)
 }
 
+// CredentialsStore is content of credentials file: credentials of all known targets
+// (TAP platforms) stored by target name, together with name of currently used target.
+type CredentialsStore struct {
+	CurrentTarget string                 `json:"current-target"`
+	Targets       map[string]Credentials `json:"targets"`
+}
+
+// SetCliHome makes CLI keep its files in dir instead of directory taken from TAP_CLI_HOME or default one
+func SetCliHome(dir string) {
+	cliConfigDir = dir
+	CredsPath = filepath.Join(cliConfigDir, "credentials.json")
+}
+
+func getCliConfigDir() string {
+	if dir := os.Getenv(CliHomeEnvVar); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".tap-cli")
+}
+
+// GetCredentials returns credentials override, credentials of target selected in Config
+// or of current target. os.ErrNotExist is returned when there is no target to use
+// or when user logged out from it.
 func (c *Config) GetCredentials() (Credentials, error) {
+	if c.CredentialsOverride != nil {
+		return *c.CredentialsOverride, nil
+	}
 
-	creds := Credentials{}
+	store, err := c.GetCredentialsStore()
+	if err != nil {
+		return Credentials{}, err
+	}
 
-	b, err := ioutil.ReadFile(CredsPath)
+	name := c.Target
+	if name == "" {
+		name = store.CurrentTarget
+	}
+	if name == "" {
+		return Credentials{}, os.ErrNotExist
+	}
+
+	creds, exists := store.Targets[name]
+	if !exists {
+		return creds, fmt.Errorf("target %q not found", name)
+	}
+	if !creds.HasToken() {
+		return creds, os.ErrNotExist
+	}
+	return c.DecryptCredentials(creds)
+}
+
+// DecryptCredentials fills Token of credentials stored with encrypted token, using passphrase from Config
+func (c *Config) DecryptCredentials(creds Credentials) (Credentials, error) {
+	if creds.EncryptedToken == "" {
+		return creds, nil
+	}
+	if c.Passphrase == nil {
+		return creds, errors.New("token is encrypted, passphrase is required to decrypt it")
+	}
+
+	passphrase, err := c.Passphrase()
 	if err != nil {
 		return creds, err
 	}
-
-	err = json.Unmarshal(b, &creds)
+	creds.Token, err = DecryptToken(creds.EncryptedToken, passphrase)
 	return creds, err
 }
 
+// SetCredentials saves credentials as target selected in Config, target with the same address
+// or new target named after address, and makes this target current one.
+// Token is encrypted with passphrase from Config when EncryptTokens is set.
 func (c *Config) SetCredentials(creds Credentials) error {
+	store, err := c.GetCredentialsStore()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	creds.EncryptedToken = ""
+	if c.EncryptTokens && creds.Token != "" {
+		if c.Passphrase == nil {
+			return errors.New("passphrase is required to encrypt token")
+		}
+		passphrase, err := c.Passphrase()
+		if err != nil {
+			return err
+		}
+		if creds.EncryptedToken, err = EncryptToken(creds.Token, passphrase); err != nil {
+			return err
+		}
+		creds.Token = ""
+	}
+
+	name := c.Target
+	if name == "" {
+		name = findTargetByAddress(store, creds.Address)
+	}
+	if name == "" {
+		name = defaultTargetName(creds.Address)
+	}
+
+	store.Targets[name] = creds
+	store.CurrentTarget = name
+	return c.saveCredentialsStore(store)
+}
+
+func (c *Config) GetCredentialsStore() (CredentialsStore, error) {
+	store := CredentialsStore{Targets: make(map[string]Credentials)}
 
-	jsonBytes, err := json.Marshal(creds)
+	b, err := ioutil.ReadFile(CredsPath)
+	if err != nil {
+		return store, err
+	}
+
+	store.Targets = nil
+	if err = json.Unmarshal(b, &store); err != nil {
+		return store, err
+	}
+	if store.Targets == nil {
+		store.Targets = make(map[string]Credentials)
+		migrateSingleCredentialsFile(b, &store)
+	}
+	return store, nil
+}
+
+// migrateSingleCredentialsFile handles credentials file written by previous CLI versions,
+// which held exactly one Credentials record.
+func migrateSingleCredentialsFile(b []byte, store *CredentialsStore) {
+	creds := Credentials{}
+	if err := json.Unmarshal(b, &creds); err != nil || creds.Address == "" {
+		return
+	}
+	name := defaultTargetName(creds.Address)
+	store.Targets[name] = creds
+	store.CurrentTarget = name
+}
+
+func (c *Config) UseTarget(name string) error {
+	store, err := c.GetCredentialsStore()
+	if err != nil {
+		return err
+	}
+	if _, exists := store.Targets[name]; !exists {
+		return fmt.Errorf("target %q not found", name)
+	}
+	store.CurrentTarget = name
+	return c.saveCredentialsStore(store)
+}
+
+func (c *Config) DeleteTarget(name string) error {
+	store, err := c.GetCredentialsStore()
+	if err != nil {
+		return err
+	}
+	if _, exists := store.Targets[name]; !exists {
+		return fmt.Errorf("target %q not found", name)
+	}
+	delete(store.Targets, name)
+	if store.CurrentTarget == name {
+		store.CurrentTarget = ""
+	}
+	return c.saveCredentialsStore(store)
+}
+
+// ClearTokens removes tokens of given targets, keeping their addresses and usernames
+func (c *Config) ClearTokens(names []string) error {
+	store, err := c.GetCredentialsStore()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		creds, exists := store.Targets[name]
+		if !exists {
+			return fmt.Errorf("target %q not found", name)
+		}
+		creds.Token = ""
+		creds.EncryptedToken = ""
+		creds.TokenType = ""
+		creds.ExpiresIn = 0
+		creds.ExpiresAt = 0
+		store.Targets[name] = creds
+	}
+	return c.saveCredentialsStore(store)
+}
+
+func (c *Config) saveCredentialsStore(store CredentialsStore) error {
+
+	jsonBytes, err := json.Marshal(store)
 	if err != nil {
 		return err
 	}
 
-	err = os.MkdirAll(cliConfigDir, PERMISSIONS)
+	err = os.MkdirAll(filepath.Dir(CredsPath), DIR_PERMISSIONS)
 	if err != nil {
 		return err
 	}
 
 	err = ioutil.WriteFile(CredsPath, jsonBytes, PERMISSIONS)
-	return err
+	if err != nil {
+		return err
+	}
+	// file written by previous CLI versions could be accessible by others
+	return os.Chmod(CredsPath, PERMISSIONS)
+}
+
+// CheckCredentialsFilePermissions returns error when credentials file can be modified by other users
+// and warning when it can be read by them. Missing file is not an error.
+func CheckCredentialsFilePermissions() (warning string, err error) {
+	if runtime.GOOS == "windows" {
+		return "", nil
+	}
+	info, err := os.Stat(CredsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	mode := info.Mode().Perm()
+	if mode&0022 != 0 {
+		return "", fmt.Errorf("credentials file %s can be modified by other users (mode %04o), "+
+			"refusing to use it. Fix it with: chmod 600 %s", CredsPath, mode, CredsPath)
+	}
+	if mode&0044 != 0 {
+		return fmt.Sprintf("credentials file %s can be read by other users (mode %04o). "+
+			"Fix it with: chmod 600 %s", CredsPath, mode, CredsPath), nil
+	}
+	return "", nil
+}
+
+func findTargetByAddress(store CredentialsStore, address string) string {
+	if creds, exists := store.Targets[store.CurrentTarget]; exists && creds.Address == address {
+		return store.CurrentTarget
+	}
+	for name, creds := range store.Targets {
+		if creds.Address == address {
+			return name
+		}
+	}
+	return ""
+}
+
+func defaultTargetName(address string) string {
+	if index := strings.Index(address, "://"); index != -1 {
+		address = address[index+3:]
+	}
+	return strings.TrimSuffix(address, "/")
 }
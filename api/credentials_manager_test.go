@@ -17,11 +17,15 @@
 package api
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+const testCredsPath = "/tmp/testTapCredentialsStore.json"
+
 func TestGetCredentials(t *testing.T) {
 	Convey("Test GetCredentials should return error for wrong credentials path", t, func() {
 		CredsPath = "/notExisting"
@@ -31,4 +35,243 @@ func TestGetCredentials(t *testing.T) {
 
 		So(err, ShouldNotBeNil)
 	})
+
+	Convey("Test GetCredentials should return override without reading credentials file", t, func() {
+		CredsPath = "/notExisting"
+		override := Credentials{Address: "https://api.example.com", Token: "token", TokenType: "bearer"}
+		c := Config{CredentialsOverride: &override}
+
+		creds, err := c.GetCredentials()
+
+		So(err, ShouldBeNil)
+		So(creds, ShouldResemble, override)
+	})
+}
+
+func TestGetCliConfigDir(t *testing.T) {
+	Convey("Test getCliConfigDir", t, func() {
+		oldHome := os.Getenv(CliHomeEnvVar)
+
+		Convey("should return directory from environment when set", func() {
+			os.Setenv(CliHomeEnvVar, "/tmp/tap-cli-home")
+
+			So(getCliConfigDir(), ShouldEqual, "/tmp/tap-cli-home")
+		})
+
+		Convey("should return directory in user home by default", func() {
+			os.Unsetenv(CliHomeEnvVar)
+
+			So(getCliConfigDir(), ShouldEqual, os.Getenv("HOME")+"/.tap-cli")
+		})
+
+		Reset(func() {
+			os.Setenv(CliHomeEnvVar, oldHome)
+		})
+	})
+}
+
+func TestSetCliHome(t *testing.T) {
+	Convey("SetCliHome should move credentials file to given directory", t, func() {
+		oldDir, oldPath := cliConfigDir, CredsPath
+
+		SetCliHome("/tmp/tap-cli-home")
+
+		So(CredsPath, ShouldEqual, "/tmp/tap-cli-home/credentials.json")
+		cliConfigDir, CredsPath = oldDir, oldPath
+	})
+}
+
+func TestCredentialsStore(t *testing.T) {
+	Convey("Test credentials store", t, func() {
+		CredsPath = testCredsPath
+		os.Remove(testCredsPath)
+		c := Config{}
+		devCreds := Credentials{Address: "https://api.dev.example.com", Username: "dev", Token: "dev-token"}
+		prodCreds := Credentials{Address: "https://api.prod.example.com", Username: "prod", Token: "prod-token"}
+
+		Convey("GetCredentials should read file with single credentials written by previous versions", func() {
+			ioutil.WriteFile(testCredsPath, []byte(`{"address":"https://api.dev.example.com","username":"dev","token":"dev-token"}`), PERMISSIONS)
+
+			creds, err := c.GetCredentials()
+
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, devCreds)
+		})
+
+		Convey("SetCredentials should store target named after address and make it current", func() {
+			So(c.SetCredentials(devCreds), ShouldBeNil)
+
+			store, err := c.GetCredentialsStore()
+
+			So(err, ShouldBeNil)
+			So(store.CurrentTarget, ShouldEqual, "api.dev.example.com")
+			So(store.Targets["api.dev.example.com"], ShouldResemble, devCreds)
+		})
+
+		Convey("SetCredentials should store target under name given in Config", func() {
+			So(c.SetCredentials(devCreds), ShouldBeNil)
+			So((&Config{Target: "prod"}).SetCredentials(prodCreds), ShouldBeNil)
+
+			store, err := c.GetCredentialsStore()
+
+			So(err, ShouldBeNil)
+			So(store.CurrentTarget, ShouldEqual, "prod")
+			So(len(store.Targets), ShouldEqual, 2)
+		})
+
+		Convey("GetCredentials should prefer target given in Config over current one", func() {
+			So((&Config{Target: "dev"}).SetCredentials(devCreds), ShouldBeNil)
+			So((&Config{Target: "prod"}).SetCredentials(prodCreds), ShouldBeNil)
+
+			creds, err := (&Config{Target: "dev"}).GetCredentials()
+
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, devCreds)
+		})
+
+		Convey("GetCredentials should fail for unknown target", func() {
+			So(c.SetCredentials(devCreds), ShouldBeNil)
+
+			_, err := (&Config{Target: "unknown"}).GetCredentials()
+
+			So(err.Error(), ShouldContainSubstring, "not found")
+		})
+
+		Convey("UseTarget should switch current target", func() {
+			So((&Config{Target: "dev"}).SetCredentials(devCreds), ShouldBeNil)
+			So((&Config{Target: "prod"}).SetCredentials(prodCreds), ShouldBeNil)
+
+			So(c.UseTarget("dev"), ShouldBeNil)
+			creds, err := c.GetCredentials()
+
+			So(err, ShouldBeNil)
+			So(creds, ShouldResemble, devCreds)
+			So(c.UseTarget("unknown"), ShouldNotBeNil)
+		})
+
+		Convey("DeleteTarget should remove current target so that login is required", func() {
+			So((&Config{Target: "dev"}).SetCredentials(devCreds), ShouldBeNil)
+
+			So(c.DeleteTarget("dev"), ShouldBeNil)
+			_, err := c.GetCredentials()
+
+			So(os.IsNotExist(err), ShouldBeTrue)
+			So(c.DeleteTarget("dev"), ShouldNotBeNil)
+		})
+
+		Convey("ClearTokens should remove token so that login is required, but keep target", func() {
+			So((&Config{Target: "dev"}).SetCredentials(devCreds), ShouldBeNil)
+
+			So(c.ClearTokens([]string{"dev"}), ShouldBeNil)
+			_, err := c.GetCredentials()
+			store, storeErr := c.GetCredentialsStore()
+
+			So(os.IsNotExist(err), ShouldBeTrue)
+			So(storeErr, ShouldBeNil)
+			So(store.Targets["dev"].Address, ShouldEqual, devCreds.Address)
+			So(store.Targets["dev"].Token, ShouldBeEmpty)
+		})
+
+		Reset(func() {
+			os.Remove(testCredsPath)
+		})
+	})
+}
+
+func TestCredentialsFilePermissions(t *testing.T) {
+	Convey("Test credentials file permissions", t, func() {
+		CredsPath = testCredsPath
+		os.Remove(testCredsPath)
+		c := Config{}
+
+		Convey("SetCredentials should make credentials file accessible by owner only", func() {
+			ioutil.WriteFile(testCredsPath, []byte("{}"), 0744)
+			os.Chmod(testCredsPath, 0744)
+
+			So(c.SetCredentials(Credentials{Address: "https://api.example.com", Token: "token"}), ShouldBeNil)
+			info, err := os.Stat(testCredsPath)
+
+			So(err, ShouldBeNil)
+			So(info.Mode().Perm(), ShouldEqual, PERMISSIONS)
+		})
+
+		Convey("CheckCredentialsFilePermissions should warn when file can be read by others", func() {
+			ioutil.WriteFile(testCredsPath, []byte("{}"), PERMISSIONS)
+			os.Chmod(testCredsPath, 0644)
+
+			warning, err := CheckCredentialsFilePermissions()
+
+			So(err, ShouldBeNil)
+			So(warning, ShouldContainSubstring, "chmod 600")
+		})
+
+		Convey("CheckCredentialsFilePermissions should refuse file which can be modified by others", func() {
+			ioutil.WriteFile(testCredsPath, []byte("{}"), PERMISSIONS)
+			os.Chmod(testCredsPath, 0666)
+
+			_, err := CheckCredentialsFilePermissions()
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("CheckCredentialsFilePermissions should accept missing file", func() {
+			warning, err := CheckCredentialsFilePermissions()
+
+			So(err, ShouldBeNil)
+			So(warning, ShouldBeEmpty)
+		})
+
+		Reset(func() {
+			os.Remove(testCredsPath)
+		})
+	})
+}
+
+func TestEncryptedTokens(t *testing.T) {
+	Convey("Test credentials with encrypted token", t, func() {
+		CredsPath = testCredsPath
+		os.Remove(testCredsPath)
+		passphrase := "secret"
+		c := Config{EncryptTokens: true, Passphrase: func() (string, error) { return passphrase, nil }}
+		creds := Credentials{Address: "https://api.example.com", Token: "token"}
+
+		Convey("token should not be stored in plain text", func() {
+			So(c.SetCredentials(creds), ShouldBeNil)
+			content, err := ioutil.ReadFile(testCredsPath)
+
+			So(err, ShouldBeNil)
+			So(string(content), ShouldNotContainSubstring, `"token":"token"`)
+			So(string(content), ShouldContainSubstring, "encrypted-token")
+		})
+
+		Convey("GetCredentials should return decrypted token", func() {
+			So(c.SetCredentials(creds), ShouldBeNil)
+
+			result, err := c.GetCredentials()
+
+			So(err, ShouldBeNil)
+			So(result.Token, ShouldEqual, "token")
+		})
+
+		Convey("GetCredentials should fail for wrong passphrase", func() {
+			So(c.SetCredentials(creds), ShouldBeNil)
+			passphrase = "wrong"
+
+			_, err := c.GetCredentials()
+
+			So(err, ShouldEqual, ErrWrongPassphrase)
+		})
+
+		Convey("GetCredentials should fail when there is no passphrase", func() {
+			So(c.SetCredentials(creds), ShouldBeNil)
+
+			_, err := (&Config{}).GetCredentials()
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			os.Remove(testCredsPath)
+		})
+	})
 }
@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
+)
+
+const expectContinueTimeout = 5 * time.Second
+
+// CertificateFiles are paths to PEM files used to secure connection to target:
+// bundle of trusted CAs and client certificate with its key for mutual TLS
+type CertificateFiles struct {
+	CACert     string `json:"ca-cert,omitempty"`
+	ClientCert string `json:"client-cert,omitempty"`
+	ClientKey  string `json:"client-key,omitempty"`
+}
+
+// NewHttpClient returns client for connections to target, which trusts only CAs from CACert file
+// and presents client certificate, when these files are given
+func NewHttpClient(skipSSLValidation bool, files CertificateFiles) (*http.Client, error) {
+	if (files.ClientCert == "") != (files.ClientKey == "") {
+		return nil, errors.New("client certificate and client key have to be given together")
+	}
+
+	client, transport, err := commonHttp.GetHttpClientWithCustomSSLValidation(skipSSLValidation)
+	if err != nil {
+		return nil, err
+	}
+	transport.ExpectContinueTimeout = expectContinueTimeout
+
+	if files.CACert != "" {
+		caPem, err := ioutil.ReadFile(files.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", files.CACert)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if files.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(files.ClientCert, files.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	return client, nil
+}
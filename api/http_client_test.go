@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testCACertPath = "/tmp/testTapCA.pem"
+
+func TestNewHttpClient(t *testing.T) {
+	Convey("Test NewHttpClient", t, func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		Convey("should trust server with certificate signed by CA from file", func() {
+			serverCert := server.TLS.Certificates[0].Certificate[0]
+			ioutil.WriteFile(testCACertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert}), PERMISSIONS)
+
+			client, err := NewHttpClient(false, CertificateFiles{CACert: testCACertPath})
+			So(err, ShouldBeNil)
+			_, err = client.Get(server.URL)
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("should not trust server with unknown certificate", func() {
+			client, err := NewHttpClient(false, CertificateFiles{})
+			So(err, ShouldBeNil)
+			_, err = client.Get(server.URL)
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should fail when CA file contains no certificates", func() {
+			ioutil.WriteFile(testCACertPath, []byte("not a certificate"), PERMISSIONS)
+
+			_, err := NewHttpClient(false, CertificateFiles{CACert: testCACertPath})
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("should fail when client certificate is given without key", func() {
+			_, err := NewHttpClient(false, CertificateFiles{ClientCert: testCACertPath})
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			server.Close()
+			os.Remove(testCACertPath)
+		})
+	})
+}
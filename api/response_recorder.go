@@ -0,0 +1,83 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ResponseRecorder remembers outcome of requests sent by HTTP clients it wraps. API client does not expose
+// status codes in its errors, so they are taken from responses instead.
+type ResponseRecorder struct {
+	mutex        sync.Mutex
+	unauthorized bool
+	madeChanges  bool
+}
+
+// Wrap makes client send its requests through recorder
+func (r *ResponseRecorder) Wrap(client *http.Client) {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &recordingTransport{base: base, recorder: r}
+}
+
+// Unauthorized tells if any request was rejected with 401 (Unauthorized) status
+func (r *ResponseRecorder) Unauthorized() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.unauthorized
+}
+
+// MadeChanges tells if any request other than GET or HEAD could have been applied by server, i.e. it was
+// accepted or its response was not received
+func (r *ResponseRecorder) MadeChanges() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.madeChanges
+}
+
+// Reset forgets recorded responses
+func (r *ResponseRecorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.unauthorized, r.madeChanges = false, false
+}
+
+func (r *ResponseRecorder) record(request *http.Request, response *http.Response, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err == nil && response.StatusCode == http.StatusUnauthorized {
+		r.unauthorized = true
+	}
+	if request.Method != http.MethodGet && request.Method != http.MethodHead && (err != nil || response.StatusCode < 400) {
+		r.madeChanges = true
+	}
+}
+
+type recordingTransport struct {
+	base     http.RoundTripper
+	recorder *ResponseRecorder
+}
+
+func (t *recordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := t.base.RoundTrip(request)
+	t.recorder.record(request, response, err)
+	return response, err
+}
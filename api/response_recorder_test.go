@@ -0,0 +1,71 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	Convey("Test ResponseRecorder", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/expired" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		recorder := &ResponseRecorder{}
+		client := &http.Client{}
+		recorder.Wrap(client)
+
+		Convey("should record rejected token", func() {
+			client.Get(server.URL + "/expired")
+
+			So(recorder.Unauthorized(), ShouldBeTrue)
+			So(recorder.MadeChanges(), ShouldBeFalse)
+		})
+
+		Convey("should record only accepted requests which can change state", func() {
+			client.Get(server.URL)
+			So(recorder.MadeChanges(), ShouldBeFalse)
+			client.Post(server.URL+"/expired", "application/json", nil)
+			So(recorder.MadeChanges(), ShouldBeFalse)
+
+			client.Post(server.URL, "application/json", nil)
+
+			So(recorder.MadeChanges(), ShouldBeTrue)
+			So(recorder.Unauthorized(), ShouldBeTrue)
+		})
+
+		Convey("should forget responses after reset", func() {
+			client.Post(server.URL+"/expired", "application/json", nil)
+			client.Post(server.URL, "application/json", nil)
+
+			recorder.Reset()
+
+			So(recorder.Unauthorized(), ShouldBeFalse)
+			So(recorder.MadeChanges(), ShouldBeFalse)
+		})
+
+		Reset(func() {
+			server.Close()
+		})
+	})
+}
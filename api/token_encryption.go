@@ -0,0 +1,93 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	passphraseSaltLength    = 16
+	passphraseKeyLength     = 32
+	passphraseKeyIterations = 100000
+)
+
+var ErrWrongPassphrase = errors.New("cannot decrypt token: wrong passphrase")
+
+// EncryptToken encrypts token with AES-GCM using key derived from passphrase.
+// Result is base64 encoded salt, nonce and sealed token.
+func EncryptToken(token, passphrase string) (string, error) {
+	salt := make([]byte, passphraseSaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+
+	gcm, err := newPassphraseCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, nonce, []byte(token), nil)
+	data := append(append(salt, nonce...), sealed...)
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func DecryptToken(encryptedToken, passphrase string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encryptedToken)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < passphraseSaltLength {
+		return "", errors.New("encrypted token is too short")
+	}
+
+	gcm, err := newPassphraseCipher(passphrase, data[:passphraseSaltLength])
+	if err != nil {
+		return "", err
+	}
+
+	data = data[passphraseSaltLength:]
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted token is too short")
+	}
+	token, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(token), nil
+}
+
+func newPassphraseCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, passphraseKeyIterations, passphraseKeyLength, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
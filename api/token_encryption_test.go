@@ -0,0 +1,35 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import "testing"
+
+func TestThatEncryptedTokenCanBeDecrypted(t *testing.T) {
+	encrypted, err := EncryptToken("token", "passphrase")
+	if err != nil {
+		t.Fatalf("encryption failed: %v", err)
+	}
+
+	decrypted, err := DecryptToken(encrypted, "passphrase")
+	if err != nil || decrypted != "token" {
+		t.Errorf("decryption returned %q, %v", decrypted, err)
+	}
+
+	if _, err = DecryptToken(encrypted, "other passphrase"); err != ErrWrongPassphrase {
+		t.Errorf("decryption with wrong passphrase returned %v", err)
+	}
+}
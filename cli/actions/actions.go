@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/trustedanalytics-ng/tap-cli/api"
 	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
@@ -28,6 +30,9 @@ import (
 
 const successMessage = "OK"
 
+// timeNow is replaced in tests
+var timeNow = time.Now
+
 type ActionsConfig struct {
 	api.Config
 }
@@ -36,12 +41,13 @@ func announceSuccessfulOperation() {
 	fmt.Println(successMessage)
 }
 
-func (a *ActionsConfig) Login(skipSSLValidation bool) error {
+func (a *ActionsConfig) Login(skipSSLValidation bool, certificateFiles api.CertificateFiles) error {
 	address, username, _ := a.ApiServiceLogin.GetLoginCredentials()
 	creds := api.Credentials{}
 	creds.Address = address
 	creds.Username = username
 	creds.SkipSSLValidation = skipSSLValidation
+	creds.CertificateFiles = certificateFiles
 
 	fmt.Println("Authenticating...")
 
@@ -62,6 +68,9 @@ func (a *ActionsConfig) Login(skipSSLValidation bool) error {
 	creds.Token = loginResp.AccessToken
 	creds.TokenType = loginResp.TokenType
 	creds.ExpiresIn = loginResp.ExpiresIn
+	if loginResp.ExpiresIn > 0 {
+		creds.ExpiresAt = timeNow().Add(time.Duration(loginResp.ExpiresIn) * time.Second).Unix()
+	}
 
 	if err = a.SetCredentials(creds); err != nil {
 		return err
@@ -72,6 +81,48 @@ func (a *ActionsConfig) Login(skipSSLValidation bool) error {
 	return nil
 }
 
+// Logout removes token of used target (or of all targets) from credentials store. API service does not
+// provide token revocation, so token stays valid on platform side until it expires.
+func (a *ActionsConfig) Logout(all bool) error {
+	store, err := a.GetCredentialsStore()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	names := []string{}
+	if all {
+		for name, creds := range store.Targets {
+			if creds.HasToken() {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	} else {
+		name := a.Config.Target
+		if name == "" {
+			name = store.CurrentTarget
+		}
+		if creds, exists := store.Targets[name]; exists && creds.HasToken() {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		fmt.Println("Not logged in")
+		return nil
+	}
+
+	if err = a.ClearTokens(names); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fmt.Printf("Logged out from target %q\n", name)
+	}
+	fmt.Println("Token was removed only from this machine, it remains valid on the platform until it expires")
+	return nil
+}
+
 func (a *ActionsConfig) Target() error {
 	creds, err := a.GetCredentials()
 	if err != nil {
@@ -80,11 +131,52 @@ func (a *ActionsConfig) Target() error {
 		}
 		return err
 	}
-	printCredentials(creds)
+	return printCredentials(creds)
+}
+
+func (a *ActionsConfig) ListTargets() error {
+	store, err := a.GetCredentialsStore()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return printTargets(store)
+}
+
+func printTargets(store api.CredentialsStore) error {
+	names := []string{}
+	for name := range store.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	printableTargets := []printer.Printable{}
+	for _, name := range names {
+		printableTargets = append(printableTargets, printer.PrintableTarget{
+			Name:        name,
+			Current:     name == store.CurrentTarget,
+			Credentials: store.Targets[name],
+		})
+	}
+	return printer.PrintList(printableTargets)
+}
+
+func (a *ActionsConfig) UseTarget(name string) error {
+	if err := a.Config.UseTarget(name); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to target %q\n", name)
+	return nil
+}
+
+func (a *ActionsConfig) DeleteTarget(name string) error {
+	if err := a.Config.DeleteTarget(name); err != nil {
+		return err
+	}
+	fmt.Printf("Target %q successfully removed\n", name)
 	return nil
 }
 
-func printCredentials(creds api.Credentials) {
+func printCredentials(creds api.Credentials) error {
 	printableCredentials := []printer.Printable{printer.PrintableCredentials{Credentials: creds}}
-	printer.PrintTable(printableCredentials)
+	return printer.PrintList(printableCredentials)
 }
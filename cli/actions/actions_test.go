@@ -20,9 +20,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 
@@ -52,16 +54,22 @@ var expectedUaaRes = uaa_connector.LoginResponse{
 }
 
 var expectedCredsFileContent = "{" +
+	"\"current-target\":\"" + url + "\"," +
+	"\"targets\":{\"" + url + "\":{" +
 	"\"address\":\"" + url + "\"," +
 	"\"username\":\"" + login + "\"," +
 	"\"token\":\"" + expectedUaaRes.AccessToken + "\"," +
 	"\"type\":\"" + expectedUaaRes.TokenType + "\"," +
 	"\"expires\":" + strconv.Itoa(expectedUaaRes.ExpiresIn) + "," +
+	"\"expires-at\":" + strconv.FormatInt(fakeNow.Unix()+int64(expectedUaaRes.ExpiresIn), 10) + "," +
 	"\"skip-ssl-validation\":" + strconv.FormatBool(skipSSLValidation) +
-	"}"
+	"}}}"
+
+var fakeNow = time.Unix(1480000000, 0)
 
 func init() {
 	test.SwitchToTestCredentialsFile()
+	timeNow = func() time.Time { return fakeNow }
 }
 
 func prepareLoginMock(c ActionsConfig, res uaa_connector.LoginResponse, status int, err error) {
@@ -98,7 +106,7 @@ func TestLoginActions(t *testing.T) {
 			prepareIntroduceMock(actionsConfig, nil)
 			prepareLoginMock(actionsConfig, expectedUaaRes, http.StatusUnauthorized, someErr)
 
-			err := actionsConfig.Login(skipSSLValidation)
+			err := actionsConfig.Login(skipSSLValidation, api.CertificateFiles{})
 
 			So(err.Error(), ShouldContainSubstring, someErr.Error())
 		})
@@ -107,7 +115,7 @@ func TestLoginActions(t *testing.T) {
 			prepareIntroduceMock(actionsConfig, nil)
 			prepareLoginMock(actionsConfig, expectedUaaRes, http.StatusInternalServerError, someErr)
 
-			err := actionsConfig.Login(skipSSLValidation)
+			err := actionsConfig.Login(skipSSLValidation, api.CertificateFiles{})
 
 			So(err.Error(), ShouldContainSubstring, someErr.Error())
 		})
@@ -115,7 +123,7 @@ func TestLoginActions(t *testing.T) {
 			prepareIntroduceMock(actionsConfig, nil)
 			prepareLoginMock(actionsConfig, expectedUaaRes, http.StatusNotFound, nil)
 
-			err := actionsConfig.Login(skipSSLValidation)
+			err := actionsConfig.Login(skipSSLValidation, api.CertificateFiles{})
 
 			So(err.Error(), ShouldContainSubstring, "incompatibility detected")
 		})
@@ -123,7 +131,7 @@ func TestLoginActions(t *testing.T) {
 			someErr := errors.New("anything")
 			prepareIntroduceMock(actionsConfig, someErr)
 
-			err := actionsConfig.Login(skipSSLValidation)
+			err := actionsConfig.Login(skipSSLValidation, api.CertificateFiles{})
 
 			So(err, ShouldEqual, someErr)
 		})
@@ -132,7 +140,7 @@ func TestLoginActions(t *testing.T) {
 			prepareLoginMock(actionsConfig, expectedUaaRes, http.StatusOK, nil)
 
 			stdout := test.CaptureStdout(func() {
-				actionsConfig.Login(skipSSLValidation)
+				actionsConfig.Login(skipSSLValidation, api.CertificateFiles{})
 			})
 
 			b, err := test.ReadCredentialsTestFile()
@@ -147,6 +155,48 @@ func TestLoginActions(t *testing.T) {
 	})
 }
 
+func TestLogoutActions(t *testing.T) {
+	Convey("Test Logout command", t, func() {
+		actionsConfig := ActionsConfig{api.Config{}}
+		test.FillCredentialsTestFile(expectedCredsFileContent)
+
+		Convey("Should remove token of current target", func() {
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.Logout(false)
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldContainSubstring, "remains valid on the platform until it expires")
+			_, err = actionsConfig.GetCredentials()
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("Should remove tokens of all targets", func() {
+			err := actionsConfig.Logout(true)
+
+			So(err, ShouldBeNil)
+			_, err = actionsConfig.GetCredentials()
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("Should do nothing when not logged in", func() {
+			test.DeleteTestCredentialsFile()
+
+			stdout := test.CaptureStdout(func() {
+				err := actionsConfig.Logout(false)
+				So(err, ShouldBeNil)
+			})
+
+			So(stdout, ShouldContainSubstring, "Not logged in")
+		})
+
+		Reset(func() {
+			test.DeleteTestCredentialsFile()
+		})
+	})
+}
+
 func TestSendInvitationCommand(t *testing.T) {
 	Convey("Test Login command", t, func() {
 		actionsConfig, mockCtrl := setupActionsTest(t)
@@ -17,76 +17,301 @@
 package actions
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"time"
 
 	apiServiceModels "github.com/trustedanalytics-ng/tap-api-service/models"
 	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
 	"github.com/trustedanalytics-ng/tap-cli/cli/archiver"
 	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
 	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
 )
 
-func (a *ActionsConfig) PushApplication(blobPath string, pushTimeout time.Duration) error {
-	blob, err := os.Open(blobPath)
+const firstUploadRetryDelay = 2 * time.Second
+
+// sleep is replaced in tests
+var sleep = time.Sleep
+
+// PushApplication uploads application archive from blobPath, repeating upload up to retries
+// times when it fails because of network problems (not at all by default). Pushed application is returned.
+func (a *ActionsConfig) PushApplication(blobPath, manifestPath string, pushTimeout time.Duration,
+	retries int) (catalogModels.Application, error) {
+
+	manifest, err := a.readManifest(manifestPath, true)
 	if err != nil {
-		return err
+		return catalogModels.Application{}, err
 	}
-	defer blob.Close()
+	return a.pushApplicationArchive(blobPath, manifest, pushTimeout, retries)
+}
+
+func (a *ActionsConfig) pushApplicationArchive(blobPath string, manifest apiServiceModels.Manifest,
+	pushTimeout time.Duration, retries int) (catalogModels.Application, error) {
 
-	pwd, err := os.Getwd()
+	progress := printer.NewUploadProgressBar()
+	app, err := a.uploadWithRetries(manifest.Name, retries, progress, func(attempt int) (catalogModels.Application, error) {
+		return a.ApplicationUploader.UploadApplicationFile(blobPath, manifest, pushTimeout, progress)
+	})
 	if err != nil {
-		return err
+		return app, err
 	}
 
-	manifestBytes, err := ioutil.ReadFile(fmt.Sprintf("%v/manifest.json", pwd))
+	return app, printApplication(app)
+}
+
+// uploadWithRetries repeats upload failed because of network or gateway problem. When request might have
+// reached API service, application is looked up first, as pushing it again would fail or create duplicate.
+func (a *ActionsConfig) uploadWithRetries(applicationName string, retries int, progress *printer.UploadProgressBar,
+	upload func(attempt int) (catalogModels.Application, error)) (catalogModels.Application, error) {
+
+	delay := firstUploadRetryDelay
+	for attempt := 1; ; attempt++ {
+		app, err := upload(attempt)
+		if err == nil {
+			progress.Complete()
+			return app, nil
+		}
+		if attempt > retries || !api.IsTransientUploadError(err) {
+			progress.Abort()
+			return app, err
+		}
+		if !api.IsUploadNotSentError(err) {
+			exists, lookupErr := a.applicationExists(applicationName)
+			if lookupErr != nil {
+				progress.Abort()
+				return app, err
+			}
+			if exists {
+				progress.Abort()
+				return app, fmt.Errorf("%v\napplication %s was created although upload failed, it is not pushed again",
+					err, applicationName)
+			}
+		}
+		progress.Printf("Upload failed: %v\nRetrying in %v (retry %d of %d)\n", err, delay, attempt, retries)
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+func (a *ActionsConfig) applicationExists(applicationName string) (bool, error) {
+	applications, err := a.ApiService.ListApplicationInstances()
 	if err != nil {
-		return err
+		return false, err
+	}
+	for _, application := range applications {
+		if application.Name == applicationName {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	manifest := apiServiceModels.Manifest{}
-	err = json.Unmarshal(manifestBytes, &manifest)
+func printApplication(app catalogModels.Application) error {
+	printableApplications := []printer.Printable{printer.PrintableRecentlyPushedApplication{Application: app}}
+	return printer.PrintList(printableApplications)
+}
+
+// readManifest validates manifest before upload. Bindings are checked against existing
+// service instances when checkBindings is set.
+func (a *ActionsConfig) readManifest(manifestPath string, checkBindings bool) (apiServiceModels.Manifest, error) {
+	appManifest, err := manifest.ReadApplicationManifest(manifestPath)
 	if err != nil {
-		return err
+		return apiServiceModels.Manifest{}, err
 	}
 
-	app, err := a.ApiService.CreateApplicationInstance(blob, manifest, pushTimeout)
+	var serviceInstances []string
+	if checkBindings && appManifest.HasBindings() {
+		instances, err := a.ApiService.ListServiceInstances()
+		if err != nil {
+			return apiServiceModels.Manifest{}, err
+		}
+		serviceInstances = []string{}
+		for _, instance := range instances {
+			serviceInstances = append(serviceInstances, instance.Name)
+		}
+	}
+	return appManifest.Validate(serviceInstances)
+}
+
+// CompressCwdAndPushAsApplication pushes current directory as application. Archive is streamed
+// directly to API service, unless it has to be kept in saveArchivePath or API service requires
+// known content length - then temporary archive file is created.
+func (a *ActionsConfig) CompressCwdAndPushAsApplication(manifestPath string, pushTimeout time.Duration, retries int,
+	saveArchivePath string) (catalogModels.Application, error) {
+
+	folder, err := os.Getwd()
 	if err != nil {
-		return err
+		return catalogModels.Application{}, err
 	}
 
-	printApplication(app)
-	return nil
+	if saveArchivePath == "" {
+		app, err := a.streamCwdAsApplication(folder, manifestPath, pushTimeout, retries)
+		if err != api.ErrContentLengthRequired {
+			return app, err
+		}
+		fmt.Println("API service requires known size of application archive, pushing it through temporary file")
+	}
+
+	archivePath, err := archiver.CreateApplicationArchive(folder)
+	if err != nil {
+		return catalogModels.Application{}, err
+	}
+	app, err := a.PushApplication(archivePath, manifestPath, pushTimeout, retries)
+	err2 := keepOrRemoveArchive(archivePath, saveArchivePath)
+	if err != nil {
+		return app, err
+	}
+	return app, err2
 }
 
-func printApplication(app catalogModels.Application) {
-	printableApplications := []printer.Printable{printer.PrintableRecentlyPushedApplication{Application: app}}
-	printer.PrintTable(printableApplications)
+func (a *ActionsConfig) streamCwdAsApplication(folder, manifestPath string, pushTimeout time.Duration,
+	retries int) (catalogModels.Application, error) {
+
+	manifest, err := a.readManifest(manifestPath, true)
+	if err != nil {
+		return catalogModels.Application{}, err
+	}
+	if err = archiver.CheckRunScript(folder); err != nil {
+		return catalogModels.Application{}, err
+	}
+
+	progress := printer.NewUploadProgressBar()
+	app, err := a.uploadWithRetries(manifest.Name, retries, progress, func(attempt int) (catalogModels.Application, error) {
+		writeArchive := func(writer io.Writer) error {
+			return archiver.WriteApplicationArchive(folder, writer, func(entry archiver.ArchiveEntry) {
+				// archive content is listed only once, although it is created again on each retry
+				if attempt == 1 {
+					progress.Printf("Added to archive: %v\n", entry.Path)
+				}
+			})
+		}
+		return a.ApplicationUploader.UploadApplication(writeArchive, manifest, pushTimeout, progress)
+	})
+	if err != nil {
+		return app, err
+	}
+
+	return app, printApplication(app)
 }
 
-func (a *ActionsConfig) CompressCwdAndPushAsApplication(pushTimeout time.Duration) error {
+// DryRunPushApplication prints content of application archive created from current directory
+// (or of the given one) and validates manifest and run script, without pushing application.
+// Bindings in manifest are not checked, as it does not require login.
+func (a *ActionsConfig) DryRunPushApplication(archivePath, manifestPath, saveArchivePath string) error {
+	if archivePath != "" {
+		return a.dryRunPush(archivePath, manifestPath)
+	}
+
 	folder, err := os.Getwd()
 	if err != nil {
 		return err
 	}
-	archivePath, err := archiver.CreateApplicationArchive(folder)
+	if archivePath, err = archiver.CreateApplicationArchiveQuietly(folder); err != nil {
+		return err
+	}
+	err = a.dryRunPush(archivePath, manifestPath)
+	err2 := keepOrRemoveArchive(archivePath, saveArchivePath)
 	if err != nil {
 		return err
 	}
-	err = a.PushApplication(archivePath, pushTimeout)
-	err2 := os.Remove(archivePath)
+	return err2
+}
+
+func (a *ActionsConfig) dryRunPush(archivePath, manifestPath string) error {
+	entries, err := archiver.ListArchive(archivePath)
 	if err != nil {
 		return err
 	}
-	if err2 != nil {
-		return err2
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return err
+	}
+	compressedSize := info.Size()
+
+	printableEntries := []printer.Printable{}
+	filesCount, uncompressedSize := 0, int64(0)
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+		printableEntries = append(printableEntries, printer.PrintableArchiveEntry{ArchiveEntry: entry})
+		filesCount++
+		uncompressedSize += entry.Size
+	}
+	if err := printer.PrintList(printableEntries); err != nil {
+		return err
+	}
+	// summary goes to stderr, so that list of files can be read in any output format
+	fmt.Fprintf(os.Stderr, "Total: %d files, %s uncompressed, %s compressed\n", filesCount,
+		printer.FormatSize(uncompressedSize), printer.FormatSize(compressedSize))
+
+	if _, err = a.readManifest(manifestPath, false); err != nil {
+		return err
+	}
+	if err = validateRunScript(entries); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Dry run finished, application was not pushed")
+	return nil
+}
+
+func validateRunScript(entries []archiver.ArchiveEntry) error {
+	for _, entry := range entries {
+		if entry.Path != archiver.RunScriptName {
+			continue
+		}
+		if !entry.Mode.IsRegular() {
+			return fmt.Errorf("%s in archive is not a regular file", archiver.RunScriptName)
+		}
+		if entry.Mode&0111 == 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not executable\n", archiver.RunScriptName)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is missing in archive", archiver.RunScriptName)
+}
+
+func keepOrRemoveArchive(archivePath, saveArchivePath string) error {
+	if saveArchivePath == "" {
+		return os.Remove(archivePath)
+	}
+	if err := moveFile(archivePath, saveArchivePath); err != nil {
+		return err
 	}
+	fmt.Fprintf(os.Stderr, "Archive saved to: %s\n", saveArchivePath)
 	return nil
 }
 
+// moveFile renames file, copying it when it has to be moved between file systems
+func moveFile(source, destination string) error {
+	if err := os.Rename(source, destination); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(source)
+}
+
 func (a *ActionsConfig) GetApplication(applicationName string) error {
 	applicationID, err := converter.GetApplicationID(a.Config, applicationName)
 	if err != nil {
@@ -98,9 +323,7 @@ func (a *ActionsConfig) GetApplication(applicationName string) error {
 		return err
 	}
 
-	printer.PrintFormattedJSON(applicationInstance)
-
-	return nil
+	return printer.PrintObject(printer.PrintableApplication{ApplicationInstance: applicationInstance})
 }
 
 func (a *ActionsConfig) ListApplications() error {
@@ -109,16 +332,15 @@ func (a *ActionsConfig) ListApplications() error {
 		fmt.Println("Retrieving applications list failed")
 		return err
 	}
-	printApplications(applicationInstances)
-	return nil
+	return printApplications(applicationInstances)
 }
 
-func printApplications(applications []apiServiceModels.ApplicationInstance) {
+func printApplications(applications []apiServiceModels.ApplicationInstance) error {
 	printableApplications := []printer.Printable{}
 	for _, app := range applications {
 		printableApplications = append(printableApplications, printer.PrintableApplication{ApplicationInstance: app})
 	}
-	printer.PrintTable(printableApplications)
+	return printer.PrintList(printableApplications)
 }
 
 func (a *ActionsConfig) DeleteApplication(applicationName string) error {
@@ -0,0 +1,152 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestUploadWithRetries(t *testing.T) {
+	Convey("Test retrying application upload", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		sleep = func(time.Duration) {}
+		connectionRefused := &neturl.Error{Op: "Post", URL: "http://api",
+			Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+		connectionReset := &neturl.Error{Op: "Post", URL: "http://api",
+			Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}}
+
+		uploadFailing := func(errs ...error) (func(int) (catalogModels.Application, error), *int) {
+			attempts := 0
+			return func(attempt int) (catalogModels.Application, error) {
+				attempts = attempt
+				if attempt <= len(errs) {
+					return catalogModels.Application{}, errs[attempt-1]
+				}
+				return catalogModels.Application{Name: "app"}, nil
+			}, &attempts
+		}
+
+		Convey("Should retry without lookup when request was not sent", func() {
+			upload, attempts := uploadFailing(connectionRefused)
+
+			app, err := actionsConfig.uploadWithRetries("app", 3, printer.NewUploadProgressBar(), upload)
+
+			So(err, ShouldBeNil)
+			So(app.Name, ShouldEqual, "app")
+			So(*attempts, ShouldEqual, 2)
+		})
+
+		Convey("Should retry when request was sent but application was not created", func() {
+			apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{}, nil).Times(1)
+			upload, attempts := uploadFailing(connectionReset)
+
+			_, err := actionsConfig.uploadWithRetries("app", 3, printer.NewUploadProgressBar(), upload)
+
+			So(err, ShouldBeNil)
+			So(*attempts, ShouldEqual, 2)
+		})
+
+		Convey("Should not retry when application was created", func() {
+			apiMock.EXPECT().ListApplicationInstances().
+				Return([]models.ApplicationInstance{{Name: "app"}}, nil).Times(1)
+			upload, attempts := uploadFailing(&api.UploadStatusError{StatusCode: 504})
+
+			_, err := actionsConfig.uploadWithRetries("app", 3, printer.NewUploadProgressBar(), upload)
+
+			So(err.Error(), assertions.ShouldContainSubstring, "application app was created")
+			So(*attempts, ShouldEqual, 1)
+		})
+
+		Convey("Should not retry when application lookup fails", func() {
+			apiMock.EXPECT().ListApplicationInstances().Return(nil, errors.New("unavailable")).Times(1)
+			upload, attempts := uploadFailing(connectionReset)
+
+			_, err := actionsConfig.uploadWithRetries("app", 3, printer.NewUploadProgressBar(), upload)
+
+			So(err, ShouldEqual, connectionReset)
+			So(*attempts, ShouldEqual, 1)
+		})
+
+		Convey("Should not retry when retries are not enabled", func() {
+			upload, attempts := uploadFailing(connectionRefused)
+
+			_, err := actionsConfig.uploadWithRetries("app", 0, printer.NewUploadProgressBar(), upload)
+
+			So(err, ShouldEqual, connectionRefused)
+			So(*attempts, ShouldEqual, 1)
+		})
+
+		Reset(func() {
+			sleep = time.Sleep
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestDryRunPushApplication(t *testing.T) {
+	Convey("Test dry run of pushing current directory", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		workingDir, _ := os.Getwd()
+		appDir, _ := ioutil.TempDir("", "app")
+		ioutil.WriteFile(filepath.Join(appDir, "run.sh"), []byte("#!/bin/sh\n"), 0644)
+		ioutil.WriteFile(filepath.Join(appDir, "manifest.json"), []byte(`{"name": "app", "type": "GO", "instances": 1}`), 0644)
+		os.Chdir(appDir)
+
+		Convey("Should not print warning about run script on stdout", func() {
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.DryRunPushApplication("", "manifest.json", "")
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldNotContainSubstring, "Warning")
+		})
+
+		Convey("Should fail when archive cannot be saved", func() {
+			var err error
+			test.CaptureStdout(func() {
+				err = actionsConfig.DryRunPushApplication("", "manifest.json", filepath.Join(appDir, "missing", "app.tar.gz"))
+			})
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			os.Chdir(workingDir)
+			os.RemoveAll(appDir)
+			mockCtrl.Finish()
+		})
+	})
+}
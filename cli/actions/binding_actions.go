@@ -82,32 +82,100 @@ func (a *ActionsConfig) changeInstanceBinding(operationType bindingOperationType
 	return nil
 }
 
-func (a *ActionsConfig) GetInstanceBindings(instance BindableInstance) error {
+type BindingDirection string
+
+const (
+	// BindingDirectionOutgoing are bindings of instance itself, the instances it uses
+	BindingDirectionOutgoing BindingDirection = "outgoing"
+	// BindingDirectionIncoming are bindings of other instances to the instance, the instances which use it
+	BindingDirectionIncoming BindingDirection = "incoming"
+	BindingDirectionAll      BindingDirection = "all"
+)
+
+func (a *ActionsConfig) GetInstanceBindings(instance BindableInstance, direction BindingDirection) error {
 	instanceID, instanceType, err := converter.FetchInstanceIDandType(a.Config, instance.Type, instance.Name)
 	if err != nil {
 		return err
 	}
 
-	var bindings apiServiceModels.InstanceBindings
-	if instanceType == catalogModels.InstanceTypeApplication {
-		bindings, err = a.ApiService.GetApplicationBindings(instanceID)
-	} else if instanceType == catalogModels.InstanceTypeService {
-		bindings, err = a.ApiService.GetServiceBindings(instanceID)
+	printableBindings := []printer.Printable{}
+	if direction == BindingDirectionOutgoing || direction == BindingDirectionAll {
+		var bindings apiServiceModels.InstanceBindings
+		if instanceType == catalogModels.InstanceTypeApplication {
+			bindings, err = a.ApiService.GetApplicationBindings(instanceID)
+		} else if instanceType == catalogModels.InstanceTypeService {
+			bindings, err = a.ApiService.GetServiceBindings(instanceID)
+		}
+		if err != nil {
+			return err
+		}
+		printableBindings = appendPrintableBindings(printableBindings, bindings.Resources, BindingDirectionOutgoing)
 	}
+	if direction == BindingDirectionIncoming || direction == BindingDirectionAll {
+		resources, err := a.getIncomingBindings(instanceID)
+		if err != nil {
+			return err
+		}
+		printableBindings = appendPrintableBindings(printableBindings, resources, BindingDirectionIncoming)
+	}
+
+	return printer.PrintList(printableBindings)
+}
+
+// getIncomingBindings finds instances having binding of given instance, API does not provide them directly
+func (a *ActionsConfig) getIncomingBindings(instanceID string) ([]apiServiceModels.InstanceBindingsResource, error) {
+	services, err := a.ApiService.ListServiceInstances()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	applications, err := a.ApiService.ListApplicationInstances()
+	if err != nil {
+		return nil, err
 	}
 
-	printBindings(bindings)
-	return nil
+	resources := []apiServiceModels.InstanceBindingsResource{}
+	for _, application := range applications {
+		if hasBindingOf(application.Bindings, instanceID) {
+			resources = append(resources, apiServiceModels.InstanceBindingsResource{
+				InstanceBindingsEntity: apiServiceModels.InstanceBindingsEntity{
+					AppGUID:         application.Id,
+					AppInstanceName: application.Name,
+				},
+			})
+		}
+	}
+	for _, service := range services {
+		if hasBindingOf(service.Bindings, instanceID) {
+			resources = append(resources, apiServiceModels.InstanceBindingsResource{
+				InstanceBindingsEntity: apiServiceModels.InstanceBindingsEntity{
+					ServiceInstanceGUID: service.Id,
+					ServiceInstanceName: service.Name,
+				},
+			})
+		}
+	}
+	return resources, nil
 }
 
-func printBindings(bindings apiServiceModels.InstanceBindings) {
-	printableBindings := []printer.Printable{}
-	for _, resource := range bindings.Resources {
-		printableBindings = append(printableBindings, printer.PrintableResource{InstanceBindingsResource: resource})
+func hasBindingOf(bindings []catalogModels.InstanceBindings, instanceID string) bool {
+	for _, binding := range bindings {
+		if binding.Id == instanceID {
+			return true
+		}
+	}
+	return false
+}
+
+func appendPrintableBindings(printableBindings []printer.Printable, resources []apiServiceModels.InstanceBindingsResource,
+	direction BindingDirection) []printer.Printable {
+
+	for _, resource := range resources {
+		printableBindings = append(printableBindings, printer.PrintableResource{
+			InstanceBindingsResource: resource,
+			Direction:                string(direction),
+		})
 	}
-	printer.PrintTable(printableBindings)
+	return printableBindings
 }
 
 func (a *ActionsConfig) handleUnbindOperation(srcID string, srcType catalogModels.InstanceType, dstType catalogModels.InstanceType, dstID string) error {
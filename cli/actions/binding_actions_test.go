@@ -0,0 +1,85 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestGetInstanceBindings(t *testing.T) {
+	Convey("Test listing bindings of instance", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		db := BindableInstance{Name: "db", Type: catalogModels.InstanceTypeService}
+
+		services := []models.ServiceInstance{
+			{Id: "db-id", Name: "db", Bindings: []catalogModels.InstanceBindings{{Id: "cache-id"}}},
+			{Id: "cache-id", Name: "cache"},
+			{Id: "backup-id", Name: "backup", Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}}},
+		}
+		apiMock.EXPECT().ListServiceInstances().Return(services, nil).AnyTimes()
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "web-id", Name: "web", Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}}},
+		}, nil).AnyTimes()
+		outgoing := models.InstanceBindings{Resources: []models.InstanceBindingsResource{
+			{InstanceBindingsEntity: models.InstanceBindingsEntity{ServiceInstanceGUID: "cache-id", ServiceInstanceName: "cache"}},
+		}}
+
+		Convey("Should list outgoing bindings of instance", func() {
+			apiMock.EXPECT().GetServiceBindings("db-id").Return(outgoing, nil)
+
+			output := test.CaptureStdout(func() {
+				So(actionsConfig.GetInstanceBindings(db, BindingDirectionOutgoing), ShouldBeNil)
+			})
+
+			So(output, ShouldContainSubstring, "| cache        | cache-id   | outgoing  |")
+			So(output, ShouldNotContainSubstring, "incoming")
+		})
+
+		Convey("Should find incoming bindings in other instances", func() {
+			output := test.CaptureStdout(func() {
+				So(actionsConfig.GetInstanceBindings(db, BindingDirectionIncoming), ShouldBeNil)
+			})
+
+			So(output, ShouldContainSubstring, "| web          | web-id     | incoming  |")
+			So(output, ShouldContainSubstring, "| backup       | backup-id  | incoming  |")
+			So(output, ShouldNotContainSubstring, "outgoing")
+		})
+
+		Convey("Should list bindings in both directions", func() {
+			apiMock.EXPECT().GetServiceBindings("db-id").Return(outgoing, nil)
+
+			output := test.CaptureStdout(func() {
+				So(actionsConfig.GetInstanceBindings(db, BindingDirectionAll), ShouldBeNil)
+			})
+
+			So(output, ShouldContainSubstring, "outgoing")
+			So(output, ShouldContainSubstring, "incoming")
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,233 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
+)
+
+type BindingGraphFormat string
+
+const (
+	BindingGraphFormatASCII   BindingGraphFormat = "ascii"
+	BindingGraphFormatDOT     BindingGraphFormat = "dot"
+	BindingGraphFormatMermaid BindingGraphFormat = "mermaid"
+)
+
+var BindingGraphFormats = []BindingGraphFormat{BindingGraphFormatASCII, BindingGraphFormatDOT, BindingGraphFormatMermaid}
+
+// bindingGraphNode is application or service instance of binding graph
+type bindingGraphNode struct {
+	Name  string
+	Type  catalogModels.InstanceType
+	State catalogModels.InstanceState
+}
+
+func (n bindingGraphNode) failed() bool {
+	return n.State == catalogModels.InstanceStateFailure
+}
+
+func (n bindingGraphNode) label() string {
+	return fmt.Sprintf("%s (%s, %s)", n.Name, strings.ToLower(string(n.Type)), n.State)
+}
+
+// bindingGraph connects each instance with instances bound to it. Edges point from dependent instance
+// (destination of binding) to its dependencies (sources of bindings), both sorted by name.
+type bindingGraph struct {
+	nodes        []bindingGraphNode
+	dependencies map[string][]string
+}
+
+var bindingGraphRenderers = map[BindingGraphFormat]func(*bindingGraph) string{
+	BindingGraphFormatASCII:   (*bindingGraph).ascii,
+	BindingGraphFormatDOT:     (*bindingGraph).dot,
+	BindingGraphFormatMermaid: (*bindingGraph).mermaid,
+}
+
+// PrintBindingGraph renders bindings of all applications and service instances of target
+func (a *ActionsConfig) PrintBindingGraph(format BindingGraphFormat) error {
+	render, supported := bindingGraphRenderers[format]
+	if !supported {
+		return fmt.Errorf("unsupported graph format: %q, use one of: %s", format, bindingGraphFormatsString())
+	}
+
+	graph, err := a.readBindingGraph()
+	if err != nil {
+		return err
+	}
+	fmt.Print(render(graph))
+	return nil
+}
+
+func bindingGraphFormatsString() string {
+	formats := []string{}
+	for _, format := range BindingGraphFormats {
+		formats = append(formats, string(format))
+	}
+	return strings.Join(formats, ",")
+}
+
+func (a *ActionsConfig) readBindingGraph() (*bindingGraph, error) {
+	env, err := a.readEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &bindingGraph{dependencies: make(map[string][]string)}
+	for _, service := range env.services {
+		graph.nodes = append(graph.nodes, bindingGraphNode{Name: service.Name, Type: catalogModels.InstanceTypeService, State: service.State})
+	}
+	for _, application := range env.applications {
+		graph.nodes = append(graph.nodes, bindingGraphNode{Name: application.Name, Type: catalogModels.InstanceTypeApplication, State: application.State})
+	}
+	sort.Sort(bindingGraphNodesByName(graph.nodes))
+
+	for _, binding := range env.bindings {
+		graph.dependencies[binding.Dst] = append(graph.dependencies[binding.Dst], binding.Src)
+	}
+	for _, dependencies := range graph.dependencies {
+		sort.Strings(dependencies)
+	}
+	return graph, nil
+}
+
+type bindingGraphNodesByName []bindingGraphNode
+
+func (n bindingGraphNodesByName) Len() int           { return len(n) }
+func (n bindingGraphNodesByName) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
+func (n bindingGraphNodesByName) Less(i, j int) bool { return n[i].Name < n[j].Name }
+
+func (g *bindingGraph) node(name string) bindingGraphNode {
+	for _, node := range g.nodes {
+		if node.Name == name {
+			return node
+		}
+	}
+	return bindingGraphNode{Name: name}
+}
+
+// ascii renders trees of dependencies starting from instances no other instance depends on.
+// Instances which are only part of cycles are used as roots as well, repeated cycle is marked.
+func (g *bindingGraph) ascii() string {
+	isDependency := make(map[string]bool)
+	for _, dependencies := range g.dependencies {
+		for _, dependency := range dependencies {
+			isDependency[dependency] = true
+		}
+	}
+
+	out := &bytes.Buffer{}
+	visited := make(map[string]bool)
+	for _, node := range g.nodes {
+		if !isDependency[node.Name] {
+			g.asciiTree(out, node.Name, "", "", map[string]bool{}, visited)
+		}
+	}
+	for _, node := range g.nodes {
+		if !visited[node.Name] {
+			g.asciiTree(out, node.Name, "", "", map[string]bool{}, visited)
+		}
+	}
+	return out.String()
+}
+
+func (g *bindingGraph) asciiTree(out *bytes.Buffer, name, prefix, childPrefix string, path, visited map[string]bool) {
+	visited[name] = true
+	node := g.node(name)
+	line := node.label()
+	if node.failed() {
+		line = printer.Colorize(line, printer.ColorRed)
+	}
+	if path[name] {
+		fmt.Fprintf(out, "%s%s (cycle)\n", prefix, line)
+		return
+	}
+	fmt.Fprintf(out, "%s%s\n", prefix, line)
+
+	path[name] = true
+	dependencies := g.dependencies[name]
+	for i, dependency := range dependencies {
+		if i == len(dependencies)-1 {
+			g.asciiTree(out, dependency, childPrefix+"`-- ", childPrefix+"    ", path, visited)
+		} else {
+			g.asciiTree(out, dependency, childPrefix+"|-- ", childPrefix+"|   ", path, visited)
+		}
+	}
+	delete(path, name)
+}
+
+func (g *bindingGraph) dot() string {
+	out := &bytes.Buffer{}
+	fmt.Fprintln(out, "digraph bindings {")
+	fmt.Fprintln(out, "  rankdir=LR;")
+	for _, node := range g.nodes {
+		shape := "box"
+		if node.Type == catalogModels.InstanceTypeService {
+			shape = "ellipse"
+		}
+		attributes := fmt.Sprintf("shape=%s, label=%q", shape, node.Name+"\n"+string(node.State))
+		if node.failed() {
+			attributes += `, color=red, style=filled, fillcolor="#ffcccc"`
+		}
+		fmt.Fprintf(out, "  %q [%s];\n", node.Name, attributes)
+	}
+	for _, node := range g.nodes {
+		for _, dependency := range g.dependencies[node.Name] {
+			fmt.Fprintf(out, "  %q -> %q;\n", node.Name, dependency)
+		}
+	}
+	fmt.Fprintln(out, "}")
+	return out.String()
+}
+
+func (g *bindingGraph) mermaid() string {
+	ids := make(map[string]string)
+	for i, node := range g.nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintln(out, "graph LR")
+	failed := []string{}
+	for _, node := range g.nodes {
+		label := strings.Replace(node.Name+"<br/>"+string(node.State), `"`, "#quot;", -1)
+		if node.Type == catalogModels.InstanceTypeService {
+			fmt.Fprintf(out, "  %s[(\"%s\")]\n", ids[node.Name], label)
+		} else {
+			fmt.Fprintf(out, "  %s[\"%s\"]\n", ids[node.Name], label)
+		}
+		if node.failed() {
+			failed = append(failed, ids[node.Name])
+		}
+	}
+	for _, node := range g.nodes {
+		for _, dependency := range g.dependencies[node.Name] {
+			fmt.Fprintf(out, "  %s --> %s\n", ids[node.Name], ids[dependency])
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintln(out, "  classDef failure fill:#ffcccc,stroke:#ff0000")
+		fmt.Fprintf(out, "  class %s failure\n", strings.Join(failed, ","))
+	}
+	return out.String()
+}
@@ -0,0 +1,96 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+)
+
+func TestBindingGraph(t *testing.T) {
+	Convey("Test rendering binding graph", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+
+		apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+			{Id: "db-id", Name: "db", State: catalogModels.InstanceStateRunning},
+			{Id: "cache-id", Name: "cache", State: catalogModels.InstanceStateFailure},
+		}, nil)
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "web-id", Name: "web", State: catalogModels.InstanceStateRunning,
+				Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}, {Id: "cache-id"}}},
+			{Id: "ping-id", Name: "ping", State: catalogModels.InstanceStateRunning,
+				Bindings: []catalogModels.InstanceBindings{{Id: "pong-id"}}},
+			{Id: "pong-id", Name: "pong", State: catalogModels.InstanceStateStopped,
+				Bindings: []catalogModels.InstanceBindings{{Id: "ping-id"}}},
+		}, nil)
+
+		graph, err := actionsConfig.readBindingGraph()
+		So(err, ShouldBeNil)
+
+		Convey("Should render ascii trees starting from instances nothing depends on", func() {
+			So(graph.ascii(), ShouldEqual, `web (application, RUNNING)
+|-- cache (service, FAILURE)
+`+"`"+`-- db (service, RUNNING)
+ping (application, RUNNING)
+`+"`"+`-- pong (application, STOPPED)
+    `+"`"+`-- ping (application, RUNNING) (cycle)
+`)
+		})
+
+		Convey("Should render dot graph with failed instances highlighted", func() {
+			So(graph.dot(), ShouldEqual, `digraph bindings {
+  rankdir=LR;
+  "cache" [shape=ellipse, label="cache\nFAILURE", color=red, style=filled, fillcolor="#ffcccc"];
+  "db" [shape=ellipse, label="db\nRUNNING"];
+  "ping" [shape=box, label="ping\nRUNNING"];
+  "pong" [shape=box, label="pong\nSTOPPED"];
+  "web" [shape=box, label="web\nRUNNING"];
+  "ping" -> "pong";
+  "pong" -> "ping";
+  "web" -> "cache";
+  "web" -> "db";
+}
+`)
+		})
+
+		Convey("Should render mermaid graph with failed instances highlighted", func() {
+			So(graph.mermaid(), ShouldEqual, `graph LR
+  n0[("cache<br/>FAILURE")]
+  n1[("db<br/>RUNNING")]
+  n2["ping<br/>RUNNING"]
+  n3["pong<br/>STOPPED"]
+  n4["web<br/>RUNNING"]
+  n2 --> n3
+  n3 --> n2
+  n4 --> n0
+  n4 --> n1
+  classDef failure fill:#ffcccc,stroke:#ff0000
+  class n0 failure
+`)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -0,0 +1,168 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"fmt"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+)
+
+// DeletionPlan deletes resource together with its dependents. Other instances are unbound from deleted
+// instances first, then service instances created from deleted offering are deleted and the resource
+// itself is deleted by the last change.
+type DeletionPlan struct {
+	StackPlan
+	Resource   string
+	Dependents []string
+}
+
+// HasDependents tells if anything has to be unbound or deleted before the resource
+func (p *DeletionPlan) HasDependents() bool {
+	return len(p.Dependents) > 0
+}
+
+// PrintDependents lists instances depending on the resource
+func (p *DeletionPlan) PrintDependents() {
+	for _, dependent := range p.Dependents {
+		fmt.Printf("  * %s\n", dependent)
+	}
+}
+
+func (p *DeletionPlan) dependsOn(format string, args ...interface{}) {
+	p.Dependents = append(p.Dependents, fmt.Sprintf(format, args...))
+}
+
+// PlanInstanceDeletion finds instances using application or service instance. Offering plan dependencies
+// between service instances are not reported by API, so they are not taken into account.
+func (a *ActionsConfig) PlanInstanceDeletion(instanceType catalogModels.InstanceType, instanceName string) (*DeletionPlan, error) {
+	env, err := a.readEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := env.instanceKind(instanceType, instanceName); !exists {
+		return nil, fmt.Errorf("cannot find instance with name: %s", instanceName)
+	}
+
+	plan := &DeletionPlan{Resource: instanceKindName(instanceType) + " " + instanceName}
+	a.planUnbindingOfUsers(plan, env, instanceType, instanceName)
+	if instanceType == catalogModels.InstanceTypeApplication {
+		plan.add(StackOperationDelete, func() error {
+			return a.DeleteApplication(instanceName)
+		}, "delete application %s", instanceName)
+	} else {
+		plan.add(StackOperationDelete, func() error {
+			return a.DeleteService(instanceName)
+		}, "delete service %s", instanceName)
+	}
+	return plan, nil
+}
+
+// PlanOfferingDeletion finds service instances created from offering and instances using them.
+// Service instances are deleted before the offering, which is deleted after they are gone.
+func (a *ActionsConfig) PlanOfferingDeletion(offeringName string) (*DeletionPlan, error) {
+	offeringID, err := converter.GetOfferingID(a.Config, offeringName)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot fetch service id: %v", err.Error())
+	}
+	env, err := a.readEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &DeletionPlan{Resource: "offering " + offeringName}
+	for _, name := range sortedKeys(env.services) {
+		name := name
+		if env.services[name].OfferingId != offeringID {
+			continue
+		}
+		plan.dependsOn("service instance %s is created from offering %s", name, offeringName)
+		a.planUnbindingOfUsers(plan, env, catalogModels.InstanceTypeService, name)
+		plan.add(StackOperationDelete, func() error {
+			if err := a.DeleteService(name); err != nil {
+				return err
+			}
+			return a.WaitForInstance(catalogModels.InstanceTypeService, name, DeletedCondition(), DefaultWaitTimeout)
+		}, "delete service %s", name)
+	}
+	plan.add(StackOperationDelete, func() error {
+		return a.DeleteOffering(offeringName)
+	}, "delete offering %s", offeringName)
+	return plan, nil
+}
+
+// planUnbindingOfUsers unbinds instance from instances which have it among their bindings. Bindings
+// point to instance id, so users are found by id and unbound from instance of given type, as application
+// and service instance can have the same name. Every unbinding is awaited, so that the instance is not
+// deleted while it is still bound.
+func (a *ActionsConfig) planUnbindingOfUsers(plan *DeletionPlan, env *environment,
+	instanceType catalogModels.InstanceType, instanceName string) {
+
+	instanceID := env.services[instanceName].Id
+	if instanceType == catalogModels.InstanceTypeApplication {
+		instanceID = env.applications[instanceName].Id
+	}
+	src := BindableInstance{Name: instanceName, Type: instanceType}
+
+	planUnbinding := func(dst BindableInstance, dstID string, bindings []catalogModels.InstanceBindings) {
+		if dstID == instanceID || !contains(bindingIDs(bindings), instanceID) {
+			return
+		}
+		plan.dependsOn("%s %s uses %s", instanceKindName(dst.Type), dst.Name, instanceName)
+		plan.add(StackOperationDelete, func() error {
+			if err := a.UnbindInstance(src, dst); err != nil {
+				return err
+			}
+			return a.WaitForInstance(dst.Type, dst.Name, UnboundCondition(instanceID), DefaultWaitTimeout)
+		}, "unbind %s from %s", instanceName, dst.Name)
+	}
+	for _, name := range sortedKeys(env.services) {
+		service := env.services[name]
+		planUnbinding(BindableInstance{Name: name, Type: catalogModels.InstanceTypeService}, service.Id, service.Bindings)
+	}
+	for _, name := range sortedKeys(env.applications) {
+		application := env.applications[name]
+		planUnbinding(BindableInstance{Name: name, Type: catalogModels.InstanceTypeApplication}, application.Id, application.Bindings)
+	}
+}
+
+// ApplyDeletionPlan unbinds and deletes dependents one by one, then deletes the resource
+func (a *ActionsConfig) ApplyDeletionPlan(plan *DeletionPlan) error {
+	if !plan.HasDependents() {
+		return plan.Changes[0].apply()
+	}
+	return applyChanges(plan.Changes)
+}
+
+// instanceKind returns human readable kind of instance with given name, if it exists
+func (e *environment) instanceKind(instanceType catalogModels.InstanceType, name string) (string, bool) {
+	if _, isService := e.services[name]; isService && instanceType != catalogModels.InstanceTypeApplication {
+		return instanceKindName(catalogModels.InstanceTypeService), true
+	}
+	if _, isApplication := e.applications[name]; isApplication && instanceType != catalogModels.InstanceTypeService {
+		return instanceKindName(catalogModels.InstanceTypeApplication), true
+	}
+	return "", false
+}
+
+func instanceKindName(instanceType catalogModels.InstanceType) string {
+	if instanceType == catalogModels.InstanceTypeApplication {
+		return "application"
+	}
+	return "service instance"
+}
@@ -0,0 +1,151 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/golang/mock/gomock"
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestPlanDeletion(t *testing.T) {
+	Convey("Test planning deletion with dependents", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+
+		apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+			{Id: "db-id", Name: "db", OfferingId: "postgresql-id"},
+			{Id: "replica-id", Name: "replica", OfferingId: "postgresql-id", Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}}},
+			{Id: "cache-id", Name: "cache", OfferingId: "redis-id"},
+		}, nil).AnyTimes()
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "web-id", Name: "web", Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}, {Id: "cache-id"}}},
+			{Id: "db-app-id", Name: "db"},
+		}, nil).AnyTimes()
+
+		Convey("Should unbind instances using service instance before deleting it", func() {
+			plan, err := actionsConfig.PlanInstanceDeletion(catalogModels.InstanceTypeService, "db")
+
+			So(err, ShouldBeNil)
+			So(plan.Resource, ShouldEqual, "service instance db")
+			So(plan.Dependents, ShouldResemble, []string{"service instance replica uses db", "application web uses db"})
+			So(descriptionsOf(&plan.StackPlan), ShouldResemble, []string{
+				"- unbind db from replica",
+				"- unbind db from web",
+				"- delete service db",
+			})
+		})
+
+		Convey("Should delete application nothing depends on right away", func() {
+			plan, err := actionsConfig.PlanInstanceDeletion(catalogModels.InstanceTypeApplication, "web")
+
+			So(err, ShouldBeNil)
+			So(plan.HasDependents(), ShouldBeFalse)
+			So(descriptionsOf(&plan.StackPlan), ShouldResemble, []string{"- delete application web"})
+		})
+
+		Convey("Should not take users of service instance for users of application with the same name", func() {
+			plan, err := actionsConfig.PlanInstanceDeletion(catalogModels.InstanceTypeApplication, "db")
+
+			So(err, ShouldBeNil)
+			So(plan.HasDependents(), ShouldBeFalse)
+			So(descriptionsOf(&plan.StackPlan), ShouldResemble, []string{"- delete application db"})
+		})
+
+		Convey("Should fail when instance does not exist", func() {
+			_, err := actionsConfig.PlanInstanceDeletion(catalogModels.InstanceTypeApplication, "cache")
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "cannot find instance with name: cache")
+		})
+
+		Convey("Should delete service instances of offering and unbind them first", func() {
+			apiMock.EXPECT().GetOfferings().Return([]models.Offering{
+				test.NewFakeOffering(map[string]string{"name": "postgresql", "offering_id": "postgresql-id"}),
+			}, nil)
+
+			plan, err := actionsConfig.PlanOfferingDeletion("postgresql")
+
+			So(err, ShouldBeNil)
+			So(plan.Dependents, ShouldResemble, []string{
+				"service instance db is created from offering postgresql",
+				"service instance replica uses db",
+				"application web uses db",
+				"service instance replica is created from offering postgresql",
+			})
+			So(descriptionsOf(&plan.StackPlan), ShouldResemble, []string{
+				"- unbind db from replica",
+				"- unbind db from web",
+				"- delete service db",
+				"- delete service replica",
+				"- delete offering postgresql",
+			})
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestApplyDeletionPlan(t *testing.T) {
+	Convey("Test applying deletion plan with dependents", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		sleep = func(time.Duration) {}
+
+		services := []models.ServiceInstance{{Id: "db-id", Name: "db"}}
+		bound := []models.ApplicationInstance{{Id: "web-id", Name: "web", Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}}}}
+		unbound := []models.ApplicationInstance{{Id: "web-id", Name: "web"}}
+
+		Convey("Should delete service instance only after it is unbound", func() {
+			gomock.InOrder(
+				apiMock.EXPECT().ListServiceInstances().Return(services, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(bound, nil),
+				apiMock.EXPECT().ListServiceInstances().Return(services, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(bound, nil),
+				apiMock.EXPECT().UnbindServiceFromApplicationInstance("db-id", "web-id").Return(http.StatusAccepted, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(bound, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(unbound, nil),
+				apiMock.EXPECT().ListServiceInstances().Return(services, nil),
+				apiMock.EXPECT().DeleteServiceInstance("db-id").Return(nil),
+			)
+
+			var err error
+			test.CaptureStdout(func() {
+				plan, planErr := actionsConfig.PlanInstanceDeletion(catalogModels.InstanceTypeService, "db")
+				So(planErr, ShouldBeNil)
+				err = actionsConfig.ApplyDeletionPlan(plan)
+			})
+
+			So(err, ShouldBeNil)
+		})
+
+		Reset(func() {
+			sleep = time.Sleep
+			mockCtrl.Finish()
+		})
+	})
+}
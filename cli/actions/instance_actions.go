@@ -39,37 +39,6 @@ func (a *ActionsConfig) deleteInstance(df deletingFunction, instanceType catalog
 	return nil
 }
 
-func (a *ActionsConfig) GetInstanceLogs(instanceName string) error {
-	instanceID, instanceType, err := converter.FetchInstanceIDandType(a.Config, converter.InstanceTypeBoth, instanceName)
-	if err != nil {
-		return err
-	}
-
-	if err != nil {
-		return err
-	}
-
-	logs := make(map[string]string)
-	if instanceType == catalogModels.InstanceTypeApplication {
-		logs, err = a.ApiService.GetApplicationLogs(instanceID)
-		if err != nil {
-			return err
-		}
-	}
-	if instanceType == catalogModels.InstanceTypeService {
-		logs, err = a.ApiService.GetServiceLogs(instanceID)
-		if err != nil {
-			return err
-		}
-	}
-
-	for container, log := range logs {
-		fmt.Printf("%s:\n\n%s\n", container, log)
-	}
-
-	return nil
-}
-
 func (a *ActionsConfig) changeState(scf stateChangingFunction, instanceType catalogModels.InstanceType, instanceName string) error {
 	instanceID, _, err := converter.FetchInstanceIDandType(a.Config, instanceType, instanceName)
 	if err != nil {
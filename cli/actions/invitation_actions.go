@@ -46,16 +46,15 @@ func (a *ActionsConfig) ListInvitations() error {
 		fmt.Println("Listing invitations failed")
 		return err
 	}
-	printInvitations(invitations)
-	return nil
+	return printInvitations(invitations)
 }
 
-func printInvitations(invitations []string) {
+func printInvitations(invitations []string) error {
 	printableInvitations := []printer.Printable{}
 	for _, inv := range invitations {
 		printableInvitations = append(printableInvitations, printer.PrintableInvitation{Email: inv})
 	}
-	printer.PrintTable(printableInvitations)
+	return printer.PrintList(printableInvitations)
 }
 
 func (a *ActionsConfig) DeleteInvitation(email string) error {
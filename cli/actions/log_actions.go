@@ -0,0 +1,215 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+)
+
+const logsPollInterval = 2 * time.Second
+
+// LogOptions narrow down and follow logs of instance containers
+type LogOptions struct {
+	// Follow makes logs being polled and printed as they arrive
+	Follow bool
+	// Tail limits initial output to last lines of every container, 0 means all lines
+	Tail int
+	// Since skips lines with timestamps older than given duration, 0 means no limit
+	Since time.Duration
+	// Container limits output to single container
+	Container string
+}
+
+func (o LogOptions) isSet() bool {
+	return o.Follow || o.Tail > 0 || o.Since > 0 || o.Container != ""
+}
+
+var logTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+func (a *ActionsConfig) GetInstanceLogs(instanceName string, options LogOptions) error {
+	instanceID, instanceType, err := converter.FetchInstanceIDandType(a.Config, converter.InstanceTypeBoth, instanceName)
+	if err != nil {
+		return err
+	}
+
+	logs, err := a.fetchInstanceLogs(instanceID, instanceType)
+	if err != nil {
+		return err
+	}
+
+	if !options.isSet() {
+		for _, container := range sortedContainers(logs) {
+			fmt.Printf("%s:\n\n%s\n", container, logs[container])
+		}
+		return nil
+	}
+
+	if options.Container != "" {
+		if _, ok := logs[options.Container]; !ok {
+			return fmt.Errorf("container %q not found in instance %s, available containers: %s",
+				options.Container, instanceName, strings.Join(sortedContainers(logs), ", "))
+		}
+	}
+
+	printed := make(map[string][]string)
+	for {
+		for _, container := range sortedContainers(logs) {
+			if options.Container != "" && container != options.Container {
+				continue
+			}
+			lines := splitLogLines(logs[container])
+			var toPrint []string
+			if previous, ok := printed[container]; ok {
+				toPrint = newLogLines(previous, lines)
+			} else {
+				toPrint = tailLogLines(lines, options.Tail)
+			}
+			printed[container] = lines
+
+			for _, line := range filterLogLinesSince(toPrint, options.Since) {
+				fmt.Printf("[%s] %s\n", container, line)
+			}
+		}
+
+		if !options.Follow {
+			return nil
+		}
+		sleep(logsPollInterval)
+
+		if logs, err = a.fetchInstanceLogs(instanceID, instanceType); err != nil {
+			return err
+		}
+	}
+}
+
+func (a *ActionsConfig) fetchInstanceLogs(instanceID string, instanceType catalogModels.InstanceType) (map[string]string, error) {
+	switch instanceType {
+	case catalogModels.InstanceTypeApplication:
+		return a.ApiService.GetApplicationLogs(instanceID)
+	case catalogModels.InstanceTypeService:
+		return a.ApiService.GetServiceLogs(instanceID)
+	}
+	return map[string]string{}, nil
+}
+
+func sortedContainers(logs map[string]string) []string {
+	containers := make([]string, 0, len(logs))
+	for container := range logs {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+	return containers
+}
+
+func splitLogLines(log string) []string {
+	log = strings.TrimRight(log, "\n")
+	if log == "" {
+		return []string{}
+	}
+	return strings.Split(log, "\n")
+}
+
+func tailLogLines(lines []string, tail int) []string {
+	if tail <= 0 || tail >= len(lines) {
+		return lines
+	}
+	return lines[len(lines)-tail:]
+}
+
+// newLogLines returns lines of current log which were not present in previously fetched one.
+// Logs returned by API may be truncated from the beginning, so the end of previous log is looked up
+// in current one. If it cannot be found (e.g. container was restarted) whole current log is new.
+func newLogLines(previous, current []string) []string {
+	if len(previous) == 0 {
+		return current
+	}
+	last := previous[len(previous)-1]
+	for i := len(current) - 1; i >= 0; i-- {
+		if current[i] == last && endsWith(previous, current[:i+1]) {
+			return current[i+1:]
+		}
+	}
+	return current
+}
+
+// endsWith checks if lines overlap with the end of previous ones
+func endsWith(previous, lines []string) bool {
+	if len(lines) > len(previous) {
+		lines = lines[len(lines)-len(previous):]
+	}
+	offset := len(previous) - len(lines)
+	for i := range lines {
+		if previous[offset+i] != lines[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// filterLogLinesSince drops lines with timestamp older than since. Lines without timestamp
+// (e.g. stack traces) share the timestamp of nearest preceding line which has one.
+// Lines preceding first timestamp are kept as their age cannot be determined.
+func filterLogLinesSince(lines []string, since time.Duration) []string {
+	if since <= 0 {
+		return lines
+	}
+	limit := timeNow().Add(-since)
+
+	filtered := []string{}
+	keep := true
+	for _, line := range lines {
+		if timestamp, ok := parseLogTimestamp(line); ok {
+			keep = !timestamp.Before(limit)
+		}
+		if keep {
+			filtered = append(filtered, line)
+		}
+	}
+	return filtered
+}
+
+func parseLogTimestamp(line string) (time.Time, bool) {
+	line = strings.TrimLeft(line, "[")
+	for _, layout := range logTimestampLayouts {
+		fields := strings.Count(layout, " ") + 1
+		candidate := strings.Join(firstFields(line, fields), " ")
+		candidate = strings.TrimRight(candidate, "]")
+		if timestamp, err := time.Parse(layout, candidate); err == nil {
+			return timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func firstFields(line string, n int) []string {
+	fields := strings.SplitN(line, " ", n+1)
+	if len(fields) > n {
+		fields = fields[:n]
+	}
+	return fields
+}
@@ -0,0 +1,123 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestGetInstanceLogs(t *testing.T) {
+	Convey("Test getting instance logs", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		sleep = func(time.Duration) {}
+		apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{}, nil).AnyTimes()
+		apiMock.EXPECT().ListApplicationInstances().
+			Return([]models.ApplicationInstance{{Id: "app-id", Name: "app"}}, nil).AnyTimes()
+
+		Convey("Should print last lines of chosen container with prefix", func() {
+			logs := map[string]string{"app": "a1\na2\na3\n", "sidecar": "s1\n"}
+			apiMock.EXPECT().GetApplicationLogs("app-id").Return(logs, nil).Times(1)
+
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.GetInstanceLogs("app", LogOptions{Tail: 2, Container: "app"})
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldEqual, "[app] a2\n[app] a3\n")
+		})
+
+		Convey("Should fail when container does not exist", func() {
+			apiMock.EXPECT().GetApplicationLogs("app-id").Return(map[string]string{"app": "a1"}, nil).Times(1)
+
+			err := actionsConfig.GetInstanceLogs("app", LogOptions{Container: "db"})
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "available containers: app")
+		})
+
+		Convey("Should print only new lines when following", func() {
+			gomock.InOrder(
+				apiMock.EXPECT().GetApplicationLogs("app-id").Return(map[string]string{"app": "a1\na2"}, nil),
+				apiMock.EXPECT().GetApplicationLogs("app-id").Return(map[string]string{"app": "a2\na3\na4"}, nil),
+				apiMock.EXPECT().GetApplicationLogs("app-id").Return(nil, errors.New("connection refused")),
+			)
+
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.GetInstanceLogs("app", LogOptions{Follow: true})
+			})
+
+			So(err, ShouldNotBeNil)
+			So(stdout, ShouldEqual, "[app] a1\n[app] a2\n[app] a3\n[app] a4\n")
+		})
+
+		Reset(func() {
+			sleep = time.Sleep
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestNewLogLines(t *testing.T) {
+	Convey("Test finding new log lines", t, func() {
+		Convey("Should return appended lines", func() {
+			So(newLogLines([]string{"a", "b"}, []string{"a", "b", "c"}), ShouldResemble, []string{"c"})
+		})
+
+		Convey("Should return lines appended to log truncated from the beginning", func() {
+			So(newLogLines([]string{"a", "b", "c"}, []string{"b", "c", "d", "e"}), ShouldResemble, []string{"d", "e"})
+		})
+
+		Convey("Should not be confused by repeated lines", func() {
+			So(newLogLines([]string{"x", "ok"}, []string{"x", "ok", "y", "ok"}), ShouldResemble, []string{"y", "ok"})
+		})
+
+		Convey("Should return whole log when previous one is not found", func() {
+			So(newLogLines([]string{"a", "b"}, []string{"c", "d"}), ShouldResemble, []string{"c", "d"})
+		})
+	})
+}
+
+func TestFilterLogLinesSince(t *testing.T) {
+	Convey("Test filtering log lines by time", t, func() {
+		lines := []string{
+			"2016-11-24T15:03:00Z old",
+			"  at old.stack.trace",
+			"[2016-11-24 15:06:00.123] recent",
+			"  at recent.stack.trace",
+		}
+
+		Convey("Should keep recent lines together with lines without timestamp", func() {
+			So(filterLogLinesSince(lines, 2*time.Minute), ShouldResemble, lines[2:])
+		})
+
+		Convey("Should keep all lines when duration is not set", func() {
+			So(filterLogLinesSince(lines, 0), ShouldResemble, lines)
+		})
+	})
+}
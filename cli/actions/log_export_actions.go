@@ -0,0 +1,232 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+)
+
+const (
+	logExportIndexFileName    = "index.json"
+	logExportInstanceFileName = "instance.json"
+)
+
+// LogExportIndex describes files written by ExportInstanceLogs
+type LogExportIndex struct {
+	ExportedAt time.Time              `json:"exportedAt"`
+	Instances  []ExportedInstanceLogs `json:"instances"`
+}
+
+type ExportedInstanceLogs struct {
+	Name         string                     `json:"name"`
+	Id           string                     `json:"id"`
+	Type         catalogModels.InstanceType `json:"type"`
+	InstanceFile string                     `json:"instanceFile"`
+	Containers   []ExportedContainerLog     `json:"containers"`
+}
+
+type ExportedContainerLog struct {
+	Container string `json:"container"`
+	File      string `json:"file"`
+	Lines     int    `json:"lines"`
+	Size      int    `json:"size"`
+}
+
+// ExportInstanceLogs writes logs of every container of given instances into separate files in dir,
+// together with instance details and index of written files. When archivePath is given,
+// the same files are bundled into gzipped tar archive.
+func (a *ActionsConfig) ExportInstanceLogs(instanceNames []string, dir, archivePath string) error {
+	type instanceToExport struct {
+		name, id     string
+		instanceType catalogModels.InstanceType
+	}
+	instances := []instanceToExport{}
+	for _, name := range instanceNames {
+		id, instanceType, err := converter.FetchInstanceIDandType(a.Config, converter.InstanceTypeBoth, name)
+		if err != nil {
+			return err
+		}
+		instances = append(instances, instanceToExport{name: name, id: id, instanceType: instanceType})
+	}
+
+	writer, err := newLogExportWriter(dir, archivePath)
+	if err != nil {
+		return err
+	}
+	defer writer.close()
+
+	index := LogExportIndex{ExportedAt: timeNow().UTC(), Instances: []ExportedInstanceLogs{}}
+	for _, instance := range instances {
+		exported, err := a.exportLogsOfInstance(writer, instance.name, instance.id, instance.instanceType)
+		if err != nil {
+			return fmt.Errorf("cannot export logs of %s: %v", instance.name, err)
+		}
+		index.Instances = append(index.Instances, exported)
+	}
+
+	indexContent, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = writer.write(logExportIndexFileName, indexContent); err != nil {
+		return err
+	}
+	if err = writer.close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Logs of %d instance(s) exported to %s\n", len(instances), dir)
+	if archivePath != "" {
+		fmt.Printf("Bundle saved to %s\n", archivePath)
+	}
+	announceSuccessfulOperation()
+	return nil
+}
+
+func (a *ActionsConfig) exportLogsOfInstance(writer *logExportWriter, name, id string,
+	instanceType catalogModels.InstanceType) (ExportedInstanceLogs, error) {
+
+	exported := ExportedInstanceLogs{Name: name, Id: id, Type: instanceType, Containers: []ExportedContainerLog{}}
+
+	var instance interface{}
+	var err error
+	if instanceType == catalogModels.InstanceTypeApplication {
+		instance, err = a.ApiService.GetApplicationInstance(id)
+	} else {
+		instance, err = a.ApiService.GetServiceInstance(id)
+	}
+	if err != nil {
+		return exported, err
+	}
+	instanceContent, err := json.MarshalIndent(instance, "", "  ")
+	if err != nil {
+		return exported, err
+	}
+	exported.InstanceFile = path.Join(exportFileName(name), logExportInstanceFileName)
+	if err = writer.write(exported.InstanceFile, instanceContent); err != nil {
+		return exported, err
+	}
+
+	logs, err := a.fetchInstanceLogs(id, instanceType)
+	if err != nil {
+		return exported, err
+	}
+	for _, container := range sortedContainers(logs) {
+		log := logs[container]
+		file := path.Join(exportFileName(name), exportFileName(container)+".log")
+		if err = writer.write(file, []byte(log)); err != nil {
+			return exported, err
+		}
+		exported.Containers = append(exported.Containers, ExportedContainerLog{
+			Container: container,
+			File:      file,
+			Lines:     len(splitLogLines(log)),
+			Size:      len(log),
+		})
+	}
+	return exported, nil
+}
+
+// exportFileName makes instance or container name safe to use as file name. Names which would
+// point to export directory itself or to its parent ("", "." and "..") are prefixed with "_".
+func exportFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == 0 {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_" + name
+	}
+	return name
+}
+
+// logExportWriter writes exported files into directory and, optionally, into gzipped tar bundle
+type logExportWriter struct {
+	dir         string
+	archiveFile *os.File
+	gz          *gzip.Writer
+	archive     *tar.Writer
+}
+
+func newLogExportWriter(dir, archivePath string) (*logExportWriter, error) {
+	writer := &logExportWriter{dir: dir}
+	if archivePath == "" {
+		return writer, nil
+	}
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	writer.archiveFile = archiveFile
+	writer.gz = gzip.NewWriter(archiveFile)
+	writer.archive = tar.NewWriter(writer.gz)
+	return writer, nil
+}
+
+// write stores file under path relative to export directory (and bundle)
+func (w *logExportWriter) write(relativePath string, content []byte) error {
+	filePath := filepath.Join(w.dir, filepath.FromSlash(relativePath))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filePath, content, 0644); err != nil {
+		return err
+	}
+	if w.archive == nil {
+		return nil
+	}
+	header := &tar.Header{
+		Name:    path.Join(filepath.Base(w.dir), relativePath),
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: timeNow(),
+	}
+	if err := w.archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := w.archive.Write(content)
+	return err
+}
+
+// close finishes the bundle, it is safe to call it more than once
+func (w *logExportWriter) close() error {
+	if w.archive == nil {
+		return nil
+	}
+	errs := []error{w.archive.Close(), w.gz.Close(), w.archiveFile.Close()}
+	w.archive = nil
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
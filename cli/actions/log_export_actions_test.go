@@ -0,0 +1,105 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/archiver"
+)
+
+func TestExportInstanceLogs(t *testing.T) {
+	Convey("Test exporting instance logs", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		tempDir, _ := ioutil.TempDir("", "logs-export")
+		dir := filepath.Join(tempDir, "out")
+		apiMock.EXPECT().ListServiceInstances().
+			Return([]models.ServiceInstance{{Id: "service-id", Name: "db"}}, nil).AnyTimes()
+		apiMock.EXPECT().ListApplicationInstances().
+			Return([]models.ApplicationInstance{{Id: "app-id", Name: "app"}}, nil).AnyTimes()
+
+		Convey("Should write container logs, instances and index into directory and bundle", func() {
+			apiMock.EXPECT().GetApplicationInstance("app-id").Return(models.ApplicationInstance{Id: "app-id"}, nil)
+			apiMock.EXPECT().GetApplicationLogs("app-id").
+				Return(map[string]string{"app": "a1\na2\n", "sidecar": "s1"}, nil)
+			apiMock.EXPECT().GetServiceInstance("service-id").Return(models.ServiceInstance{Id: "service-id"}, nil)
+			apiMock.EXPECT().GetServiceLogs("service-id").Return(map[string]string{"postgres": "p1"}, nil)
+			archivePath := filepath.Join(tempDir, "logs.tar.gz")
+
+			err := actionsConfig.ExportInstanceLogs([]string{"app", "db"}, dir, archivePath)
+
+			So(err, ShouldBeNil)
+			log, _ := ioutil.ReadFile(filepath.Join(dir, "app", "app.log"))
+			So(string(log), ShouldEqual, "a1\na2\n")
+			_, err = os.Stat(filepath.Join(dir, "db", "instance.json"))
+			So(err, ShouldBeNil)
+
+			indexContent, _ := ioutil.ReadFile(filepath.Join(dir, "index.json"))
+			index := LogExportIndex{}
+			So(json.Unmarshal(indexContent, &index), ShouldBeNil)
+			So(index.Instances, ShouldHaveLength, 2)
+			So(index.Instances[0].Containers, ShouldResemble, []ExportedContainerLog{
+				{Container: "app", File: "app/app.log", Lines: 2, Size: 6},
+				{Container: "sidecar", File: "app/sidecar.log", Lines: 1, Size: 2},
+			})
+
+			entries, err := archiver.ListArchive(archivePath)
+			So(err, ShouldBeNil)
+			So(entries, ShouldHaveLength, 6)
+			So(entries[len(entries)-1].Path, ShouldEqual, "out/index.json")
+		})
+
+		Convey("Should fail before writing anything when instance does not exist", func() {
+			err := actionsConfig.ExportInstanceLogs([]string{"app", "missing"}, dir, "")
+
+			So(err, ShouldNotBeNil)
+			_, err = os.Stat(dir)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Reset(func() {
+			os.RemoveAll(tempDir)
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestExportFileName(t *testing.T) {
+	Convey("Test making names safe to use as file names", t, func() {
+		testCases := map[string]string{
+			"app":      "app",
+			"a/b\\c:d": "a_b_c_d",
+			"..":       "_..",
+			".":        "_.",
+			"":         "_",
+			"../etc":   ".._etc",
+			"..app":    "..app",
+		}
+		for name, expected := range testCases {
+			So(exportFileName(name), ShouldEqual, expected)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+const maxConcurrentLogFetches = 8
+
+const (
+	LogSeverityDebug = "DEBUG"
+	LogSeverityInfo  = "INFO"
+	LogSeverityWarn  = "WARN"
+	LogSeverityError = "ERROR"
+)
+
+var logSeverityRanks = map[string]int{
+	LogSeverityDebug: 0,
+	LogSeverityInfo:  1,
+	LogSeverityWarn:  2,
+	LogSeverityError: 3,
+}
+
+var logSeverityAliases = map[string]string{
+	"TRACE":    LogSeverityDebug,
+	"DEBUG":    LogSeverityDebug,
+	"INFO":     LogSeverityInfo,
+	"NOTICE":   LogSeverityInfo,
+	"WARN":     LogSeverityWarn,
+	"WARNING":  LogSeverityWarn,
+	"ERR":      LogSeverityError,
+	"ERROR":    LogSeverityError,
+	"SEVERE":   LogSeverityError,
+	"CRITICAL": LogSeverityError,
+	"FATAL":    LogSeverityError,
+	"PANIC":    LogSeverityError,
+}
+
+var (
+	// level=error (logfmt) or "level":"error" (JSON)
+	logLevelFieldRegexp = regexp.MustCompile(`(?i)\b(?:level|severity)["']?\s*[=:]\s*["']?([a-z]+)`)
+	// E1124 15:06:40.000000 (glog)
+	logGlogRegexp = regexp.MustCompile(`^([DIWEF])\d{4} \d{2}:\d{2}:\d{2}`)
+	// ERROR, [WARN], WARNING: etc. anywhere in the line
+	logLevelWordRegexp = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERR|ERROR|SEVERE|CRITICAL|FATAL|PANIC)\b`)
+)
+
+var glogSeverities = map[string]string{
+	"D": LogSeverityDebug,
+	"I": LogSeverityInfo,
+	"W": LogSeverityWarn,
+	"E": LogSeverityError,
+	"F": LogSeverityError,
+}
+
+// LogGrepOptions choose instances which logs are searched and lines which are printed
+type LogGrepOptions struct {
+	InstanceNames   []string
+	AllApplications bool
+	AllServices     bool
+	Pattern         string
+	// Severity, when set, limits matches to lines of given or higher severity
+	Severity string
+}
+
+type instanceReference struct {
+	name, id     string
+	instanceType catalogModels.InstanceType
+}
+
+type instanceLogsResult struct {
+	logs map[string]string
+	err  error
+}
+
+// ParseLogSeverity returns one of ERROR, WARN, INFO, DEBUG severities for given name
+func ParseLogSeverity(name string) (string, error) {
+	severity, ok := logSeverityAliases[strings.ToUpper(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown severity %q, expected one of: %s, %s, %s, %s", name,
+			LogSeverityError, LogSeverityWarn, LogSeverityInfo, LogSeverityDebug)
+	}
+	return severity, nil
+}
+
+// GrepInstanceLogs fetches logs of many instances concurrently and prints lines matching pattern,
+// prefixed with instance and container names
+func (a *ActionsConfig) GrepInstanceLogs(options LogGrepOptions) error {
+	pattern, err := regexp.Compile(options.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	minimalSeverity := ""
+	if options.Severity != "" {
+		if minimalSeverity, err = ParseLogSeverity(options.Severity); err != nil {
+			return err
+		}
+	}
+
+	instances, err := a.findInstancesForLogs(options)
+	if err != nil {
+		return err
+	}
+
+	results := a.fetchLogsConcurrently(instances)
+
+	failed := 0
+	for i, instance := range instances {
+		if results[i].err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot fetch logs of %s: %v\n", instance.name, results[i].err)
+			failed++
+			continue
+		}
+		for _, container := range sortedContainers(results[i].logs) {
+			lines := splitLogLines(results[i].logs[container])
+			for _, line := range grepLogLines(lines, pattern, minimalSeverity) {
+				fmt.Printf("[%s/%s] %s\n", instance.name, container, line)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("cannot fetch logs of %d of %d instance(s)", failed, len(instances))
+	}
+	return nil
+}
+
+func (a *ActionsConfig) findInstancesForLogs(options LogGrepOptions) ([]instanceReference, error) {
+	applications, services := []instanceReference{}, []instanceReference{}
+	if options.AllApplications || len(options.InstanceNames) > 0 {
+		applicationInstances, err := a.ApiService.ListApplicationInstances()
+		if err != nil {
+			return nil, err
+		}
+		for _, application := range applicationInstances {
+			applications = append(applications,
+				instanceReference{application.Name, application.Id, catalogModels.InstanceTypeApplication})
+		}
+	}
+	if options.AllServices || len(options.InstanceNames) > 0 {
+		serviceInstances, err := a.ApiService.ListServiceInstances()
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range serviceInstances {
+			services = append(services,
+				instanceReference{service.Name, service.Id, catalogModels.InstanceTypeService})
+		}
+	}
+
+	if len(options.InstanceNames) == 0 {
+		return append(applications, services...), nil
+	}
+
+	instances := []instanceReference{}
+	for _, name := range options.InstanceNames {
+		found := false
+		for _, instance := range append(services, applications...) {
+			if instance.name == name {
+				instances = append(instances, instance)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("cannot find instance with name: " + name)
+		}
+	}
+	return instances, nil
+}
+
+// fetchLogsConcurrently returns logs of instances, in the same order as instances
+func (a *ActionsConfig) fetchLogsConcurrently(instances []instanceReference) []instanceLogsResult {
+	results := make([]instanceLogsResult, len(instances))
+	semaphore := make(chan struct{}, maxConcurrentLogFetches)
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		wg.Add(1)
+		go func(i int, instance instanceReference) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			logs, err := a.fetchInstanceLogs(instance.id, instance.instanceType)
+			results[i] = instanceLogsResult{logs: logs, err: err}
+		}(i, instance)
+	}
+	wg.Wait()
+	return results
+}
+
+// grepLogLines returns lines matching pattern and having at least minimalSeverity, if it is given.
+// Lines without detectable severity (e.g. stack traces) share severity of preceding line.
+func grepLogLines(lines []string, pattern *regexp.Regexp, minimalSeverity string) []string {
+	matching := []string{}
+	severity := ""
+	for _, line := range lines {
+		if detected := detectLogSeverity(line); detected != "" {
+			severity = detected
+		}
+		if minimalSeverity != "" && (severity == "" || logSeverityRanks[severity] < logSeverityRanks[minimalSeverity]) {
+			continue
+		}
+		if pattern.MatchString(line) {
+			matching = append(matching, line)
+		}
+	}
+	return matching
+}
+
+// detectLogSeverity recognizes severity in logfmt, JSON, glog and plain "LEVEL message" formats
+func detectLogSeverity(line string) string {
+	if match := logLevelFieldRegexp.FindStringSubmatch(line); match != nil {
+		if severity, ok := logSeverityAliases[strings.ToUpper(match[1])]; ok {
+			return severity
+		}
+	}
+	if match := logGlogRegexp.FindStringSubmatch(line); match != nil {
+		return glogSeverities[match[1]]
+	}
+	if match := logLevelWordRegexp.FindStringSubmatch(line); match != nil {
+		return logSeverityAliases[match[1]]
+	}
+	return ""
+}
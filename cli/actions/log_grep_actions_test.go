@@ -0,0 +1,116 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestGrepInstanceLogs(t *testing.T) {
+	Convey("Test searching logs of many instances", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "app1-id", Name: "app1"}, {Id: "app2-id", Name: "app2"},
+		}, nil).AnyTimes()
+		apiMock.EXPECT().ListServiceInstances().
+			Return([]models.ServiceInstance{{Id: "db-id", Name: "db"}}, nil).AnyTimes()
+
+		Convey("Should print matching lines of all applications prefixed with instance and container", func() {
+			apiMock.EXPECT().GetApplicationLogs("app1-id").
+				Return(map[string]string{"app": "INFO started\nERROR timeout\n"}, nil)
+			apiMock.EXPECT().GetApplicationLogs("app2-id").
+				Return(map[string]string{"app": "WARN timeout close"}, nil)
+
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.GrepInstanceLogs(LogGrepOptions{AllApplications: true, Pattern: "time.ut"})
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldEqual, "[app1/app] ERROR timeout\n[app2/app] WARN timeout close\n")
+		})
+
+		Convey("Should filter lines by severity", func() {
+			apiMock.EXPECT().GetServiceLogs("db-id").
+				Return(map[string]string{"postgres": "INFO timeout\nERROR timeout"}, nil)
+
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.GrepInstanceLogs(LogGrepOptions{
+					InstanceNames: []string{"db"}, Pattern: "timeout", Severity: "warning"})
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldEqual, "[db/postgres] ERROR timeout\n")
+		})
+
+		Convey("Should print logs of other instances and fail when some cannot be fetched", func() {
+			apiMock.EXPECT().GetApplicationLogs("app1-id").Return(nil, errors.New("connection refused"))
+			apiMock.EXPECT().GetApplicationLogs("app2-id").Return(map[string]string{"app": "found"}, nil)
+
+			var err error
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.GrepInstanceLogs(LogGrepOptions{InstanceNames: []string{"app1", "app2"}, Pattern: "found"})
+			})
+
+			So(err, ShouldNotBeNil)
+			So(stdout, ShouldEqual, "[app2/app] found\n")
+		})
+
+		Convey("Should reject invalid pattern, severity and unknown instance", func() {
+			So(actionsConfig.GrepInstanceLogs(LogGrepOptions{AllServices: true, Pattern: "("}), ShouldNotBeNil)
+			So(actionsConfig.GrepInstanceLogs(LogGrepOptions{AllServices: true, Pattern: ".", Severity: "LOUD"}), ShouldNotBeNil)
+			So(actionsConfig.GrepInstanceLogs(LogGrepOptions{InstanceNames: []string{"nope"}, Pattern: "."}), ShouldNotBeNil)
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestDetectLogSeverity(t *testing.T) {
+	Convey("Test detecting severity of log lines", t, func() {
+		testCases := map[string]string{
+			`time="2016-11-24T15:06:40Z" level=warning msg="disk almost full"`: LogSeverityWarn,
+			`{"level":"error","msg":"failed"}`:                                 LogSeverityError,
+			`E1124 15:06:40.000000    1 main.go:10] failed`:                    LogSeverityError,
+			`2016-11-24 15:06:40,000 INFO  [main] Started`:                     LogSeverityInfo,
+			`[FATAL] cannot bind port`:                                         LogSeverityError,
+			`    at com.example.Main.run(Main.java:10)`:                        "",
+		}
+
+		for line, severity := range testCases {
+			So(detectLogSeverity(line), ShouldEqual, severity)
+		}
+	})
+
+	Convey("Test that lines without severity inherit it from preceding line", t, func() {
+		lines := []string{"ERROR failed", "    at Main.run", "INFO retrying from Main.run"}
+
+		So(grepLogLines(lines, regexp.MustCompile("Main"), LogSeverityError), ShouldResemble, lines[1:2])
+	})
+}
@@ -17,25 +17,17 @@
 package actions
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 
 	apiServiceModels "github.com/trustedanalytics-ng/tap-api-service/models"
 	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
 	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
 )
 
-func (a *ActionsConfig) CreateOffering(jsonFilename string) error {
-	b, err := ioutil.ReadFile(jsonFilename)
-	if err != nil {
-		return err
-	}
-
-	serviceWithTemplate := apiServiceModels.ServiceDeploy{}
-
-	err = json.Unmarshal(b, &serviceWithTemplate)
+func (a *ActionsConfig) CreateOffering(manifestPath string) error {
+	serviceWithTemplate, err := manifest.ReadOfferingManifest(manifestPath)
 	if err != nil {
 		return err
 	}
@@ -74,39 +66,28 @@ func (a *ActionsConfig) GetOffering(name string) error {
 
 	for _, of := range offeringsList {
 		if of.Name == name {
-			return printSingleOffering(of)
+			return printer.PrintObject(printer.PrintableOffering{Offering: of})
 		}
 	}
 
 	return errors.New("Could not find offering with such name")
 }
 
-func printSingleOffering(of apiServiceModels.Offering) error {
-	marshalled, err := json.MarshalIndent(of, "", "  ")
-	if err != nil {
-		fmt.Println("Could not marshal fetched data")
-		return err
-	}
-	fmt.Println(string(marshalled))
-	return nil
-}
-
 func (a *ActionsConfig) ListOfferings() error {
 	offeringsList, err := a.ApiService.GetOfferings()
 	if err != nil {
 		fmt.Println("Retrieving catalog failed")
 		return err
 	}
-	printOfferings(offeringsList)
-	return nil
+	return printOfferings(offeringsList)
 }
 
-func printOfferings(offerings []apiServiceModels.Offering) {
+func printOfferings(offerings []apiServiceModels.Offering) error {
 	printableOfferings := []printer.Printable{}
 	for _, of := range offerings {
 		printableOfferings = append(printableOfferings, printer.PrintableOffering{Offering: of})
 	}
-	printer.PrintTable(printableOfferings)
+	return printer.PrintList(printableOfferings)
 }
 
 func (a *ActionsConfig) DeleteOffering(serviceName string) error {
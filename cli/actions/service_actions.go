@@ -65,9 +65,7 @@ func (a *ActionsConfig) GetService(serviceName string) error {
 		return err
 	}
 
-	printer.PrintFormattedJSON(serviceInstance)
-
-	return nil
+	return printer.PrintObject(printer.PrintableService{ServiceInstance: serviceInstance})
 }
 
 func (a *ActionsConfig) ListServices() error {
@@ -76,16 +74,15 @@ func (a *ActionsConfig) ListServices() error {
 		fmt.Println("Retrieving services list failed")
 		return err
 	}
-	printServices(services)
-	return nil
+	return printServices(services)
 }
 
-func printServices(services []apiServiceModels.ServiceInstance) {
+func printServices(services []apiServiceModels.ServiceInstance) error {
 	printableServices := []printer.Printable{}
 	for _, s := range services {
 		printableServices = append(printableServices, printer.PrintableService{ServiceInstance: s})
 	}
-	printer.PrintTable(printableServices)
+	return printer.PrintList(printableServices)
 }
 
 func (a *ActionsConfig) DeleteService(serviceName string) error {
@@ -120,12 +117,7 @@ func (a *ActionsConfig) GetServiceCredentials(instanceName string) error {
 		return fmt.Errorf("%q is not a service\n", instanceName)
 	}
 
-	for _, cred := range creds {
-		printer.PrintFormattedJSON(cred)
-		fmt.Println()
-	}
-
-	return nil
+	return printer.PrintObject(creds)
 }
 
 func (a *ActionsConfig) ExposeService(serviceID string, shouldExpose bool) error {
@@ -139,6 +131,5 @@ func (a *ActionsConfig) ExposeService(serviceID string, shouldExpose bool) error
 		return err
 	}
 
-	printer.PrintFormattedJSON(hosts)
-	return nil
+	return printer.PrintObject(hosts)
 }
@@ -0,0 +1,41 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const sessionExpiryWarningPeriod = 5 * time.Minute
+
+// ErrSessionExpired describes failure caused by token of used credentials being no longer valid
+var ErrSessionExpired = errors.New("session expired, please login again")
+
+// WarnAboutSessionExpiry prints warning on stderr when token of used credentials expires soon
+func (a *ActionsConfig) WarnAboutSessionExpiry() {
+	creds, err := a.GetCredentials()
+	if err != nil || creds.ExpiryTime().IsZero() {
+		return
+	}
+	left := creds.ExpiryTime().Sub(timeNow())
+	if left > 0 && left < sessionExpiryWarningPeriod {
+		fmt.Fprintf(os.Stderr, "Warning: session expires in %v, please login again soon\n", left-left%time.Second)
+	}
+}
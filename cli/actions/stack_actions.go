@@ -0,0 +1,497 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	apiServiceModels "github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/archiver"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
+)
+
+const (
+	StackOperationCreate = "+"
+	StackOperationUpdate = "~"
+	StackOperationDelete = "-"
+)
+
+// StackChange is single operation converging target to stack
+type StackChange struct {
+	Operation   string
+	Description string
+	apply       func() error
+}
+
+// StackPlan lists changes needed to converge target to stack, in order they are applied.
+// Warnings describe differences which cannot be converged automatically.
+type StackPlan struct {
+	Changes  []StackChange
+	Warnings []string
+}
+
+func (p *StackPlan) add(operation string, apply func() error, format string, args ...interface{}) {
+	p.Changes = append(p.Changes, StackChange{Operation: operation, Description: fmt.Sprintf(format, args...), apply: apply})
+}
+
+func (p *StackPlan) warn(format string, args ...interface{}) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
+
+// IsEmpty tells if target already matches the stack
+func (p *StackPlan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Print shows changes and warnings of the plan
+func (p *StackPlan) Print() {
+	if p.IsEmpty() {
+		fmt.Println("No changes, target matches the stack")
+	} else {
+		fmt.Println("Plan:")
+		for _, change := range p.Changes {
+			fmt.Printf("  %s %s\n", change.Operation, change.Description)
+		}
+	}
+	if len(p.Warnings) > 0 {
+		fmt.Println("Warnings:")
+		for _, warning := range p.Warnings {
+			fmt.Printf("  ! %s\n", warning)
+		}
+	}
+}
+
+// environment is current state of target, instances are indexed by name
+type environment struct {
+	services     map[string]apiServiceModels.ServiceInstance
+	applications map[string]apiServiceModels.ApplicationInstance
+	bindings     []manifest.StackBinding
+}
+
+func (a *ActionsConfig) readEnvironment() (*environment, error) {
+	services, err := a.ApiService.ListServiceInstances()
+	if err != nil {
+		return nil, err
+	}
+	applications, err := a.ApiService.ListApplicationInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &environment{
+		services:     make(map[string]apiServiceModels.ServiceInstance),
+		applications: make(map[string]apiServiceModels.ApplicationInstance),
+		bindings:     []manifest.StackBinding{},
+	}
+	names := make(map[string]string)
+	for _, service := range services {
+		env.services[service.Name] = service
+		names[service.Id] = service.Name
+	}
+	for _, application := range applications {
+		env.applications[application.Name] = application
+		names[application.Id] = application.Name
+	}
+
+	// bindings are stored in destination instance and point to source instance
+	addBindings := func(dst string, bindings []catalogModels.InstanceBindings) {
+		for _, binding := range bindings {
+			if src, exists := names[binding.Id]; exists {
+				env.bindings = append(env.bindings, manifest.StackBinding{Src: src, Dst: dst})
+			}
+		}
+	}
+	for _, service := range services {
+		addBindings(service.Name, service.Bindings)
+	}
+	for _, application := range applications {
+		addBindings(application.Name, application.Bindings)
+	}
+	sort.Sort(bindingsByName(env.bindings))
+	return env, nil
+}
+
+func (e *environment) exists(name string) bool {
+	_, isService := e.services[name]
+	_, isApplication := e.applications[name]
+	return isService || isApplication
+}
+
+func (e *environment) hasBinding(binding manifest.StackBinding) bool {
+	for _, existing := range e.bindings {
+		if existing == binding {
+			return true
+		}
+	}
+	return false
+}
+
+type bindingsByName []manifest.StackBinding
+
+func (b bindingsByName) Len() int      { return len(b) }
+func (b bindingsByName) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b bindingsByName) Less(i, j int) bool {
+	if b[i].Dst != b[j].Dst {
+		return b[i].Dst < b[j].Dst
+	}
+	return b[i].Src < b[j].Src
+}
+
+// PlanStack compares stack read from file with instances and bindings existing on target.
+// Missing service instances are created, missing applications pushed, replicas of existing ones scaled
+// and missing bindings created. With prune, instances and bindings not declared in stack are removed.
+func (a *ActionsConfig) PlanStack(stackPath string, prune bool) (*StackPlan, error) {
+	stack, err := manifest.ReadStack(stackPath)
+	if err != nil {
+		return nil, err
+	}
+	env, err := a.readEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &StackPlan{}
+	declared := make(map[string]bool)
+	for _, service := range stack.Services {
+		declared[service.Name] = true
+	}
+	for _, application := range stack.Applications {
+		declared[application.Name] = true
+	}
+
+	// application manifests are read upfront, so invalid ones stop apply before any change is made
+	manifests, declaredBindings, err := a.readStackManifests(stack, env)
+	if err != nil {
+		return nil, err
+	}
+	manifestBindings := make(map[manifest.StackBinding]bool)
+	for _, application := range stack.Applications {
+		if env.exists(application.Name) {
+			continue
+		}
+		// bindings from manifest are created together with pushed application
+		for _, service := range manifests[application.Name].Bindings {
+			manifestBindings[manifest.StackBinding{Src: service, Dst: application.Name}] = true
+		}
+	}
+
+	// created services are awaited before they are bound by later changes
+	missingBindings := missingStackBindings(stack, manifests, env, manifestBindings)
+	boundNames := make(map[string]bool)
+	for _, binding := range missingBindings {
+		boundNames[binding.Src], boundNames[binding.Dst] = true, true
+	}
+	for binding := range manifestBindings {
+		boundNames[binding.Src] = true
+	}
+
+	for _, service := range stack.Services {
+		service := service
+		if _, isApplication := env.applications[service.Name]; isApplication {
+			return nil, fmt.Errorf("service %s: application with the same name already exists", service.Name)
+		}
+		existing, exists := env.services[service.Name]
+		if !exists {
+			plan.add(StackOperationCreate, func() error {
+				if err := a.CreateServiceInstance(service.Offering, service.Plan, service.Name, service.Envs); err != nil {
+					return err
+				}
+				if !boundNames[service.Name] {
+					return nil
+				}
+				return a.WaitForInstance(catalogModels.InstanceTypeService, service.Name,
+					StateCondition(catalogModels.InstanceStateRunning), DefaultWaitTimeout)
+			}, "create service %s (offering %s, plan %s)", service.Name, service.Offering, service.Plan)
+			continue
+		}
+		warnAboutServiceDrift(plan, service, existing)
+	}
+
+	for _, application := range stack.Applications {
+		application := application
+		if _, isService := env.services[application.Name]; isService {
+			return nil, fmt.Errorf("application %s: service with the same name already exists", application.Name)
+		}
+		existing, exists := env.applications[application.Name]
+		if !exists {
+			plan.add(StackOperationCreate, func() error {
+				return a.pushStackApplication(application, manifests[application.Name])
+			}, "push application %s from %s", application.Name, application.Path)
+			continue
+		}
+		warnAboutApplicationDrift(plan, application, existing)
+		if application.Replicas != nil && *application.Replicas != existing.Replication {
+			replicas := *application.Replicas
+			plan.add(StackOperationUpdate, func() error {
+				return a.ScaleApplication(application.Name, replicas)
+			}, "scale application %s from %d to %d replicas", application.Name, existing.Replication, replicas)
+		}
+	}
+
+	for _, binding := range missingBindings {
+		binding := binding
+		plan.add(StackOperationCreate, func() error {
+			return a.BindInstance(BindableInstance{Name: binding.Src, Type: converter.InstanceTypeBoth},
+				BindableInstance{Name: binding.Dst, Type: converter.InstanceTypeBoth})
+		}, "bind %s to %s", binding.Src, binding.Dst)
+	}
+
+	if prune {
+		planPruning(a, plan, env, declared, declaredBindings)
+	}
+	return plan, nil
+}
+
+// readStackManifests reads manifests of stack applications. Returned bindings are declared either in stack
+// or in application manifests, as apply creates both.
+func (a *ActionsConfig) readStackManifests(stack manifest.Stack, env *environment) (map[string]apiServiceModels.Manifest,
+	map[manifest.StackBinding]bool, error) {
+
+	declared := make(map[string]bool)
+	declaredServices := []string{}
+	for _, service := range stack.Services {
+		declared[service.Name] = true
+		declaredServices = append(declaredServices, service.Name)
+	}
+	for _, application := range stack.Applications {
+		declared[application.Name] = true
+	}
+
+	manifests := make(map[string]apiServiceModels.Manifest)
+	declaredBindings := make(map[manifest.StackBinding]bool)
+	for _, application := range stack.Applications {
+		appManifest, err := a.readStackApplicationManifest(application, env, declaredServices)
+		if err != nil {
+			return nil, nil, fmt.Errorf("application %s: %v", application.Name, err)
+		}
+		manifests[application.Name] = appManifest
+		for _, service := range appManifest.Bindings {
+			declaredBindings[manifest.StackBinding{Src: service, Dst: application.Name}] = true
+		}
+	}
+	for _, binding := range stack.Bindings {
+		for _, name := range []string{binding.Src, binding.Dst} {
+			if !declared[name] && !env.exists(name) {
+				return nil, nil, fmt.Errorf("binding of %s to %s: instance %s is neither declared in stack nor exists",
+					binding.Src, binding.Dst, name)
+			}
+		}
+		declaredBindings[binding] = true
+	}
+	return manifests, declaredBindings, nil
+}
+
+// missingStackBindings lists bindings declared in stack and in manifests of existing applications,
+// which do not exist on target. Manifest bindings of pushed applications are created together with them.
+func missingStackBindings(stack manifest.Stack, manifests map[string]apiServiceModels.Manifest, env *environment,
+	manifestBindings map[manifest.StackBinding]bool) []manifest.StackBinding {
+
+	bindings := append([]manifest.StackBinding{}, stack.Bindings...)
+	for _, application := range stack.Applications {
+		for _, service := range manifests[application.Name].Bindings {
+			bindings = append(bindings, manifest.StackBinding{Src: service, Dst: application.Name})
+		}
+	}
+
+	missing := []manifest.StackBinding{}
+	planned := make(map[manifest.StackBinding]bool)
+	for _, binding := range bindings {
+		if env.hasBinding(binding) || manifestBindings[binding] || planned[binding] {
+			continue
+		}
+		planned[binding] = true
+		missing = append(missing, binding)
+	}
+	return missing
+}
+
+func planPruning(a *ActionsConfig, plan *StackPlan, env *environment, declared map[string]bool,
+	declaredBindings map[manifest.StackBinding]bool) {
+
+	for _, binding := range env.bindings {
+		binding := binding
+		if declaredBindings[binding] {
+			continue
+		}
+		plan.add(StackOperationDelete, func() error {
+			return a.UnbindInstance(BindableInstance{Name: binding.Src, Type: converter.InstanceTypeBoth},
+				BindableInstance{Name: binding.Dst, Type: converter.InstanceTypeBoth})
+		}, "unbind %s from %s", binding.Src, binding.Dst)
+	}
+	for _, name := range sortedKeys(env.applications) {
+		name := name
+		if !declared[name] {
+			plan.add(StackOperationDelete, func() error {
+				return a.DeleteApplication(name)
+			}, "delete application %s", name)
+		}
+	}
+	for _, name := range sortedKeys(env.services) {
+		name := name
+		if !declared[name] {
+			plan.add(StackOperationDelete, func() error {
+				return a.DeleteService(name)
+			}, "delete service %s", name)
+		}
+	}
+}
+
+// warnAboutServiceDrift reports differences of existing service instance, which cannot be changed in place
+func warnAboutServiceDrift(plan *StackPlan, service manifest.StackService, existing apiServiceModels.ServiceInstance) {
+	if existing.ServiceName != "" && !strings.EqualFold(service.Offering, existing.ServiceName) {
+		plan.warn("service %s is created from offering %s, not %s - recreate it to change offering",
+			service.Name, existing.ServiceName, service.Offering)
+	}
+	if existing.ServicePlanName != "" && !strings.EqualFold(service.Plan, existing.ServicePlanName) {
+		plan.warn("service %s uses plan %s, not %s - recreate it to change plan",
+			service.Name, existing.ServicePlanName, service.Plan)
+	}
+	for _, key := range sortedKeys(service.Envs) {
+		if value := catalogModels.GetValueFromMetadata(existing.Metadata, key); value != service.Envs[key] {
+			plan.warn("service %s has env %s=%q, not %q - recreate it to change envs",
+				service.Name, key, value, service.Envs[key])
+		}
+	}
+}
+
+func warnAboutApplicationDrift(plan *StackPlan, application manifest.StackApplication,
+	existing apiServiceModels.ApplicationInstance) {
+
+	for _, key := range sortedKeys(application.Envs) {
+		if value := catalogModels.GetValueFromMetadata(existing.Metadata, key); value != application.Envs[key] {
+			plan.warn("application %s has env %s=%q, not %q - push it again to change envs",
+				application.Name, key, value, application.Envs[key])
+		}
+	}
+}
+
+// readStackApplicationManifest reads and validates manifest of application declared in stack. Bindings
+// in manifest have to refer to services existing on target or declared in stack.
+func (a *ActionsConfig) readStackApplicationManifest(application manifest.StackApplication, env *environment,
+	declaredServices []string) (apiServiceModels.Manifest, error) {
+
+	info, err := os.Stat(application.Path)
+	if err != nil {
+		return apiServiceModels.Manifest{}, err
+	}
+	manifestPath := application.Manifest
+	if manifestPath == "" {
+		found := false
+		if info.IsDir() {
+			manifestPath, found = manifest.FindApplicationManifestIn(application.Path)
+		}
+		if !found {
+			return apiServiceModels.Manifest{}, errors.New("manifest is not given and cannot be found in application directory")
+		}
+	}
+
+	appManifest, err := a.readManifest(manifestPath, false)
+	if err != nil {
+		return appManifest, err
+	}
+	if appManifest.Name != application.Name {
+		return appManifest, fmt.Errorf("name %q in %s differs from application name in stack", appManifest.Name, manifestPath)
+	}
+	for _, service := range appManifest.Bindings {
+		if _, exists := env.services[service]; !exists && !contains(declaredServices, service) {
+			return appManifest, fmt.Errorf("service %s used in bindings in %s is neither declared in stack nor exists",
+				service, manifestPath)
+		}
+	}
+	if application.Replicas != nil {
+		appManifest.Instances = *application.Replicas
+	}
+	appManifest.Metadata = withEnvs(appManifest.Metadata, application.Envs)
+	return appManifest, nil
+}
+
+// withEnvs returns metadata with envs set, they replace entries with the same keys
+func withEnvs(metadata []catalogModels.Metadata, envs map[string]string) []catalogModels.Metadata {
+	result := []catalogModels.Metadata{}
+	for _, entry := range metadata {
+		if _, replaced := envs[entry.Id]; !replaced {
+			result = append(result, entry)
+		}
+	}
+	for _, key := range sortedKeys(envs) {
+		result = append(result, catalogModels.Metadata{Id: key, Value: envs[key]})
+	}
+	return result
+}
+
+// pushStackApplication pushes application from archive or directory given in stack
+func (a *ActionsConfig) pushStackApplication(application manifest.StackApplication, appManifest apiServiceModels.Manifest) error {
+	archivePath := application.Path
+	if info, err := os.Stat(archivePath); err != nil {
+		return err
+	} else if info.IsDir() {
+		if err = archiver.CheckRunScript(application.Path); err != nil {
+			return err
+		}
+		if archivePath, err = archiver.CreateApplicationArchiveQuietly(application.Path); err != nil {
+			return err
+		}
+		defer os.Remove(archivePath)
+	}
+	_, err := a.pushApplicationArchive(archivePath, appManifest, 0, 0)
+	return err
+}
+
+// ApplyStackPlan makes changes of the plan one by one, stopping at first failure
+func (a *ActionsConfig) ApplyStackPlan(plan *StackPlan) error {
+	if err := applyChanges(plan.Changes); err != nil {
+		return err
+	}
+	fmt.Printf("Stack applied, %d change(s) made\n", len(plan.Changes))
+	return nil
+}
+
+func applyChanges(changes []StackChange) error {
+	for i, change := range changes {
+		fmt.Printf("[%d/%d] %s %s\n", i+1, len(changes), change.Operation, change.Description)
+		if err := change.apply(); err != nil {
+			return fmt.Errorf("cannot %s: %v", change.Description, err)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns sorted keys of map with string keys
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
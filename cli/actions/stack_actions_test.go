@@ -0,0 +1,243 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+	containerBrokerModels "github.com/trustedanalytics-ng/tap-container-broker/models"
+)
+
+func TestPlanStack(t *testing.T) {
+	Convey("Test planning changes of stack", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		tempDir, _ := ioutil.TempDir("", "stack")
+		stackPath := filepath.Join(tempDir, "stack.yaml")
+		ioutil.WriteFile(filepath.Join(tempDir, "web.json"), []byte(`{"name":"web","type":"GO","instances":1}`), 0644)
+		ioutil.WriteFile(filepath.Join(tempDir, "web.tar.gz"), []byte{}, 0644)
+
+		apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+			{Id: "db-id", Name: "db", ServiceName: "postgresql", ServicePlanName: "free",
+				Metadata: []catalogModels.Metadata{{Id: "MAX_CONNECTIONS", Value: "10"}}},
+			{Id: "cache-id", Name: "cache", Bindings: []catalogModels.InstanceBindings{{Id: "legacy-id"}}},
+		}, nil).AnyTimes()
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "web-id", Name: "web", Replication: 1, Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}}},
+			{Id: "legacy-id", Name: "legacy"},
+		}, nil).AnyTimes()
+
+		Convey("Should plan creation, scaling and pruning with warnings about drift", func() {
+			ioutil.WriteFile(stackPath, []byte(`services:
+  - name: db
+    offering: postgresql
+    plan: paid
+    envs:
+      MAX_CONNECTIONS: "20"
+  - name: queue
+    offering: rabbitmq
+    plan: shared
+applications:
+  - name: web
+    path: web.tar.gz
+    manifest: web.json
+    replicas: 3
+    envs:
+      LOG_LEVEL: debug
+bindings:
+  - src: db
+    dst: web
+  - src: queue
+    dst: web
+`), 0644)
+
+			plan, err := actionsConfig.PlanStack(stackPath, true)
+
+			So(err, ShouldBeNil)
+			So(descriptionsOf(plan), ShouldResemble, []string{
+				"+ create service queue (offering rabbitmq, plan shared)",
+				"~ scale application web from 1 to 3 replicas",
+				"+ bind queue to web",
+				"- unbind legacy from cache",
+				"- delete application legacy",
+				"- delete service cache",
+			})
+			So(plan.Warnings, ShouldResemble, []string{
+				"service db uses plan free, not paid - recreate it to change plan",
+				`service db has env MAX_CONNECTIONS="10", not "20" - recreate it to change envs`,
+				`application web has env LOG_LEVEL="", not "debug" - push it again to change envs`,
+			})
+		})
+
+		Convey("Should set envs from stack over metadata from manifest", func() {
+			metadata := withEnvs([]catalogModels.Metadata{{Id: "LOG_LEVEL", Value: "info"}, {Id: "REGION", Value: "eu"}},
+				map[string]string{"LOG_LEVEL": "debug", "WORKERS": "4"})
+
+			So(metadata, ShouldResemble, []catalogModels.Metadata{
+				{Id: "REGION", Value: "eu"}, {Id: "LOG_LEVEL", Value: "debug"}, {Id: "WORKERS", Value: "4"},
+			})
+		})
+
+		Convey("Should not prune nor change anything declared in stack", func() {
+			ioutil.WriteFile(stackPath, []byte(`applications:
+  - name: web
+    path: web.tar.gz
+    manifest: web.json
+bindings:
+  - src: db
+    dst: web
+`), 0644)
+
+			plan, err := actionsConfig.PlanStack(stackPath, false)
+
+			So(err, ShouldBeNil)
+			So(plan.IsEmpty(), ShouldBeTrue)
+		})
+
+		Convey("Should bind existing application to services from its manifest", func() {
+			ioutil.WriteFile(filepath.Join(tempDir, "web.json"),
+				[]byte(`{"name":"web","type":"GO","instances":1,"bindings":["db","cache"]}`), 0644)
+			ioutil.WriteFile(stackPath, []byte(`applications:
+  - name: web
+    path: web.tar.gz
+    manifest: web.json
+`), 0644)
+
+			plan, err := actionsConfig.PlanStack(stackPath, false)
+
+			So(err, ShouldBeNil)
+			So(descriptionsOf(plan), ShouldResemble, []string{"+ bind cache to web"})
+		})
+
+		Convey("Should reject binding of unknown instance", func() {
+			ioutil.WriteFile(stackPath, []byte(`bindings:
+  - src: missing
+    dst: web
+`), 0644)
+
+			_, err := actionsConfig.PlanStack(stackPath, false)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "instance missing is neither declared in stack nor exists")
+		})
+
+		Convey("Should reject application which name differs from manifest", func() {
+			ioutil.WriteFile(stackPath, []byte(`applications:
+  - name: api
+    path: web.tar.gz
+    manifest: web.json
+`), 0644)
+
+			_, err := actionsConfig.PlanStack(stackPath, false)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `name "web" in`)
+		})
+
+		Convey("Should apply planned changes in order", func() {
+			ioutil.WriteFile(stackPath, []byte(`applications:
+  - name: web
+    path: web.tar.gz
+    manifest: web.json
+    replicas: 2
+`), 0644)
+			apiMock.EXPECT().ScaleApplicationInstance("web-id", 2).
+				Return(containerBrokerModels.MessageResponse{Message: "scaled"}, nil)
+
+			plan, err := actionsConfig.PlanStack(stackPath, false)
+			So(err, ShouldBeNil)
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.ApplyStackPlan(plan)
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldContainSubstring, "[1/1] ~ scale application web from 1 to 2 replicas")
+		})
+
+		Reset(func() {
+			os.RemoveAll(tempDir)
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestApplyStackPlan(t *testing.T) {
+	Convey("Test applying stack with created services", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		tempDir, _ := ioutil.TempDir("", "stack")
+		stackPath := filepath.Join(tempDir, "stack.yaml")
+
+		Convey("Should wait until created service is running before binding it", func() {
+			ioutil.WriteFile(stackPath, []byte(`services:
+  - name: queue
+    offering: rabbitmq
+    plan: shared
+bindings:
+  - src: queue
+    dst: web
+`), 0644)
+			queue := models.ServiceInstance{Id: "queue-id", Name: "queue", State: catalogModels.InstanceStateRunning}
+			gomock.InOrder(
+				apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{}, nil),
+				apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{queue}, nil).AnyTimes(),
+			)
+			apiMock.EXPECT().ListApplicationInstances().
+				Return([]models.ApplicationInstance{{Id: "web-id", Name: "web"}}, nil).AnyTimes()
+			apiMock.EXPECT().GetOfferings().Return([]models.Offering{
+				test.NewFakeOffering(map[string]string{"name": "rabbitmq", "offering_id": "rabbitmq-id", "plan_name": "shared", "plan_id": "shared-id"}),
+			}, nil)
+			gomock.InOrder(
+				apiMock.EXPECT().CreateServiceInstance(gomock.Any()).Return(containerBrokerModels.MessageResponse{}, nil),
+				apiMock.EXPECT().BindToApplicationInstance(gomock.Any(), "web-id").
+					Return(containerBrokerModels.MessageResponse{}, nil),
+			)
+
+			plan, err := actionsConfig.PlanStack(stackPath, false)
+			So(err, ShouldBeNil)
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.ApplyStackPlan(plan)
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldContainSubstring, "queue: state RUNNING")
+		})
+
+		Reset(func() {
+			os.RemoveAll(tempDir)
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func descriptionsOf(plan *StackPlan) []string {
+	descriptions := []string{}
+	for _, change := range plan.Changes {
+		descriptions = append(descriptions, change.Operation+" "+change.Description)
+	}
+	return descriptions
+}
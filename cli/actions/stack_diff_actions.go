@@ -0,0 +1,295 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
+	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
+)
+
+const (
+	stackKindService     = "service"
+	stackKindApplication = "application"
+	stackKindBinding     = "binding"
+)
+
+var stackDiffColors = map[string]printer.Color{
+	StackOperationCreate: printer.ColorGreen,
+	StackOperationUpdate: printer.ColorYellow,
+	StackOperationDelete: printer.ColorRed,
+}
+
+// StackDifference is single difference between two stacks. Operation tells if element exists only in
+// compared stack (StackOperationCreate), only in base stack (StackOperationDelete) or differs in both.
+type StackDifference struct {
+	Operation string `json:"operation"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Details   string `json:"details,omitempty"`
+}
+
+// StackDiff lists differences of stack To compared with base stack From
+type StackDiff struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Differences []StackDifference `json:"differences"`
+}
+
+func (d *StackDiff) add(operation, kind, name, format string, args ...interface{}) {
+	d.Differences = append(d.Differences, StackDifference{
+		Operation: operation,
+		Kind:      kind,
+		Name:      name,
+		Details:   fmt.Sprintf(format, args...),
+	})
+}
+
+// HasDrift tells if stacks differ
+func (d *StackDiff) HasDrift() bool {
+	return len(d.Differences) > 0
+}
+
+// Print shows differences colored by operation, or as json when json output format is chosen
+func (d *StackDiff) Print() {
+	if printer.GetOutputFormat() == printer.OutputFormatJSON {
+		printer.PrintFormattedJSON(d)
+		fmt.Println()
+		return
+	}
+
+	fmt.Println(printer.Colorize("--- "+d.From, printer.ColorRed))
+	fmt.Println(printer.Colorize("+++ "+d.To, printer.ColorGreen))
+	if !d.HasDrift() {
+		fmt.Println("No differences")
+		return
+	}
+	for _, difference := range d.Differences {
+		line := difference.Operation + " " + difference.Kind + " " + difference.Name
+		if difference.Details != "" {
+			line += ": " + difference.Details
+		}
+		fmt.Println(printer.Colorize(line, stackDiffColors[difference.Operation]))
+	}
+}
+
+// DiffTargets compares stack of target with stack of other target
+func (a *ActionsConfig) DiffTargets(other *ActionsConfig, fromName, toName string) (*StackDiff, error) {
+	from, err := a.exportStack()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", fromName, err)
+	}
+	to, err := other.exportStack()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", toName, err)
+	}
+	return DiffStacks(from, to, fromName, toName), nil
+}
+
+// DiffStackFile compares stack of target with stack file. Paths and manifests of applications
+// are not compared, replicas and urls only when they are declared in stack file. Envs of applications
+// are compared with metadata of their manifests merged with envs from stack file, and bindings
+// from application manifests are treated as declared in stack file, as apply pushes and creates them too.
+func (a *ActionsConfig) DiffStackFile(stackPath, targetName string) (*StackDiff, error) {
+	to, err := manifest.ReadStack(stackPath)
+	if err != nil {
+		return nil, err
+	}
+	env, err := a.readEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", targetName, err)
+	}
+	from, err := a.exportEnvironment(env)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %v", targetName, err)
+	}
+	manifests, declaredBindings, err := a.readStackManifests(to, env)
+	if err != nil {
+		return nil, err
+	}
+	for i, application := range to.Applications {
+		to.Applications[i].Envs = exportEnvs(manifests[application.Name].Metadata)
+	}
+	to.Bindings = []manifest.StackBinding{}
+	for binding := range declaredBindings {
+		to.Bindings = append(to.Bindings, binding)
+	}
+	sort.Sort(bindingsByName(to.Bindings))
+	return DiffStacks(from, to, targetName, stackPath), nil
+}
+
+// DiffStacks compares services, applications and bindings of stacks by names. Urls of applications
+// are compared only when they are given in stack to, as they are assigned by TAP.
+func DiffStacks(from, to manifest.Stack, fromName, toName string) *StackDiff {
+	diff := &StackDiff{From: fromName, To: toName, Differences: []StackDifference{}}
+	diffStackServices(diff, from.Services, to.Services)
+	diffStackApplications(diff, from.Applications, to.Applications)
+	diffStackBindings(diff, from.Bindings, to.Bindings)
+	return diff
+}
+
+func diffStackServices(diff *StackDiff, from, to []manifest.StackService) {
+	fromByName := make(map[string]manifest.StackService)
+	toByName := make(map[string]manifest.StackService)
+	names := make(map[string]bool)
+	for _, service := range from {
+		fromByName[service.Name] = service
+		names[service.Name] = true
+	}
+	for _, service := range to {
+		toByName[service.Name] = service
+		names[service.Name] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		fromService, inFrom := fromByName[name]
+		toService, inTo := toByName[name]
+		switch {
+		case !inTo:
+			diff.add(StackOperationDelete, stackKindService, name, "offering %s, plan %s", fromService.Offering, fromService.Plan)
+		case !inFrom:
+			diff.add(StackOperationCreate, stackKindService, name, "offering %s, plan %s", toService.Offering, toService.Plan)
+		default:
+			if !strings.EqualFold(fromService.Offering, toService.Offering) {
+				diff.add(StackOperationUpdate, stackKindService, name, "offering %s -> %s", fromService.Offering, toService.Offering)
+			}
+			if !strings.EqualFold(fromService.Plan, toService.Plan) {
+				diff.add(StackOperationUpdate, stackKindService, name, "plan %s -> %s", fromService.Plan, toService.Plan)
+			}
+			diffStackEnvs(diff, stackKindService, name, fromService.Envs, toService.Envs)
+		}
+	}
+}
+
+func diffStackEnvs(diff *StackDiff, kind, name string, from, to map[string]string) {
+	keys := make(map[string]bool)
+	for key := range from {
+		keys[key] = true
+	}
+	for key := range to {
+		keys[key] = true
+	}
+
+	for _, key := range sortedKeys(keys) {
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		switch {
+		case !inTo:
+			diff.add(StackOperationUpdate, kind, name, "env %s %q -> unset", key, fromValue)
+		case !inFrom:
+			diff.add(StackOperationUpdate, kind, name, "env %s unset -> %q", key, toValue)
+		case fromValue != toValue:
+			diff.add(StackOperationUpdate, kind, name, "env %s %q -> %q", key, fromValue, toValue)
+		}
+	}
+}
+
+func diffStackApplications(diff *StackDiff, from, to []manifest.StackApplication) {
+	fromByName := make(map[string]manifest.StackApplication)
+	toByName := make(map[string]manifest.StackApplication)
+	names := make(map[string]bool)
+	for _, application := range from {
+		fromByName[application.Name] = application
+		names[application.Name] = true
+	}
+	for _, application := range to {
+		toByName[application.Name] = application
+		names[application.Name] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		fromApplication, inFrom := fromByName[name]
+		toApplication, inTo := toByName[name]
+		switch {
+		case !inTo:
+			diff.add(StackOperationDelete, stackKindApplication, name, "%s", replicasDetails(fromApplication.Replicas))
+		case !inFrom:
+			diff.add(StackOperationCreate, stackKindApplication, name, "%s", replicasDetails(toApplication.Replicas))
+		default:
+			if fromApplication.Replicas != nil && toApplication.Replicas != nil &&
+				*fromApplication.Replicas != *toApplication.Replicas {
+				diff.add(StackOperationUpdate, stackKindApplication, name, "replicas %d -> %d",
+					*fromApplication.Replicas, *toApplication.Replicas)
+			}
+			diffStackEnvs(diff, stackKindApplication, name, fromApplication.Envs, toApplication.Envs)
+			if len(toApplication.Urls) > 0 && !sameUrls(fromApplication.Urls, toApplication.Urls) {
+				diff.add(StackOperationUpdate, stackKindApplication, name, "urls %s -> %s",
+					urlsDetails(fromApplication.Urls), urlsDetails(toApplication.Urls))
+			}
+		}
+	}
+}
+
+func sameUrls(from, to []string) bool {
+	if len(from) != len(to) {
+		return false
+	}
+	sortedFrom := append([]string{}, from...)
+	sortedTo := append([]string{}, to...)
+	sort.Strings(sortedFrom)
+	sort.Strings(sortedTo)
+	for i := range sortedFrom {
+		if sortedFrom[i] != sortedTo[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func urlsDetails(urls []string) string {
+	if len(urls) == 0 {
+		return "none"
+	}
+	return strings.Join(urls, ", ")
+}
+
+func replicasDetails(replicas *int) string {
+	if replicas == nil {
+		return ""
+	}
+	return fmt.Sprintf("replicas %d", *replicas)
+}
+
+func diffStackBindings(diff *StackDiff, from, to []manifest.StackBinding) {
+	fromSet := make(map[manifest.StackBinding]bool)
+	toSet := make(map[manifest.StackBinding]bool)
+	all := []manifest.StackBinding{}
+	for _, binding := range from {
+		fromSet[binding] = true
+		all = append(all, binding)
+	}
+	for _, binding := range to {
+		toSet[binding] = true
+		if !fromSet[binding] {
+			all = append(all, binding)
+		}
+	}
+	sort.Sort(bindingsByName(all))
+
+	for _, binding := range all {
+		name := binding.Src + " -> " + binding.Dst
+		switch {
+		case !toSet[binding]:
+			diff.add(StackOperationDelete, stackKindBinding, name, "")
+		case !fromSet[binding]:
+			diff.add(StackOperationCreate, stackKindBinding, name, "")
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestDiffStacks(t *testing.T) {
+	Convey("Test comparing stacks", t, func() {
+		one, three := 1, 3
+		from := manifest.Stack{
+			Services: []manifest.StackService{
+				{Name: "cache", Offering: "redis", Plan: "small"},
+				{Name: "db", Offering: "postgresql", Plan: "free", Envs: map[string]string{"MAX_CONNECTIONS": "10", "DEBUG": "1"}},
+			},
+			Applications: []manifest.StackApplication{
+				{Name: "legacy", Path: "legacy", Replicas: &one},
+				{Name: "web", Path: "web", Replicas: &one, Envs: map[string]string{"LOG_LEVEL": "info"},
+					Urls: []string{"web.staging.example.com"}},
+			},
+			Bindings: []manifest.StackBinding{{Src: "db", Dst: "web"}, {Src: "legacy", Dst: "cache"}},
+		}
+
+		Convey("Should report all differences ordered by kind and name", func() {
+			to := manifest.Stack{
+				Services: []manifest.StackService{
+					{Name: "db", Offering: "postgresql", Plan: "paid", Envs: map[string]string{"MAX_CONNECTIONS": "20", "SSL": "on"}},
+					{Name: "queue", Offering: "rabbitmq", Plan: "shared"},
+				},
+				Applications: []manifest.StackApplication{
+					{Name: "api", Path: "api"},
+					{Name: "web", Path: "web", Replicas: &three, Envs: map[string]string{"LOG_LEVEL": "debug"},
+						Urls: []string{"web.example.com"}},
+				},
+				Bindings: []manifest.StackBinding{{Src: "db", Dst: "web"}, {Src: "queue", Dst: "web"}},
+			}
+
+			diff := DiffStacks(from, to, "target staging", "target prod")
+
+			So(diff.HasDrift(), ShouldBeTrue)
+			So(diff.Differences, ShouldResemble, []StackDifference{
+				{Operation: StackOperationDelete, Kind: "service", Name: "cache", Details: "offering redis, plan small"},
+				{Operation: StackOperationUpdate, Kind: "service", Name: "db", Details: "plan free -> paid"},
+				{Operation: StackOperationUpdate, Kind: "service", Name: "db", Details: `env DEBUG "1" -> unset`},
+				{Operation: StackOperationUpdate, Kind: "service", Name: "db", Details: `env MAX_CONNECTIONS "10" -> "20"`},
+				{Operation: StackOperationUpdate, Kind: "service", Name: "db", Details: `env SSL unset -> "on"`},
+				{Operation: StackOperationCreate, Kind: "service", Name: "queue", Details: "offering rabbitmq, plan shared"},
+				{Operation: StackOperationCreate, Kind: "application", Name: "api"},
+				{Operation: StackOperationDelete, Kind: "application", Name: "legacy", Details: "replicas 1"},
+				{Operation: StackOperationUpdate, Kind: "application", Name: "web", Details: "replicas 1 -> 3"},
+				{Operation: StackOperationUpdate, Kind: "application", Name: "web", Details: `env LOG_LEVEL "info" -> "debug"`},
+				{Operation: StackOperationUpdate, Kind: "application", Name: "web", Details: "urls web.staging.example.com -> web.example.com"},
+				{Operation: StackOperationDelete, Kind: "binding", Name: "legacy -> cache"},
+				{Operation: StackOperationCreate, Kind: "binding", Name: "queue -> web"},
+			})
+		})
+
+		Convey("Should not report differences of paths nor undeclared replicas and urls", func() {
+			to := from
+			to.Applications = []manifest.StackApplication{
+				{Name: "legacy", Path: "./sources/legacy.tar.gz"},
+				{Name: "web", Path: "./web", Manifest: "web.json", Replicas: &one, Envs: map[string]string{"LOG_LEVEL": "info"}},
+			}
+
+			diff := DiffStacks(from, to, "current target", "stack.yaml")
+
+			So(diff.HasDrift(), ShouldBeFalse)
+		})
+	})
+}
+
+func TestDiffStackFile(t *testing.T) {
+	Convey("Test comparing target with stack file", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		tempDir, _ := ioutil.TempDir("", "stack")
+		stackPath := filepath.Join(tempDir, "stack.yaml")
+		ioutil.WriteFile(filepath.Join(tempDir, "web.json"),
+			[]byte(`{"name":"web","type":"GO","instances":1,"bindings":["db"],"metadata":[{"key":"LOG_LEVEL","value":"info"}]}`), 0644)
+		ioutil.WriteFile(filepath.Join(tempDir, "web.tar.gz"), []byte{}, 0644)
+
+		apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+			{Id: "db-id", Name: "db", OfferingId: "postgresql-id",
+				Metadata: []catalogModels.Metadata{{Id: catalogModels.OFFERING_PLAN_ID, Value: "free-id"}}},
+		}, nil).AnyTimes()
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "web-id", Name: "web", Replication: 1, Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}},
+				Metadata: []catalogModels.Metadata{{Id: "LOG_LEVEL", Value: "info"}}, Urls: []string{"web.example.com"}},
+		}, nil).AnyTimes()
+		apiMock.EXPECT().GetOfferings().Return([]models.Offering{
+			test.NewFakeOffering(map[string]string{"name": "postgresql", "offering_id": "postgresql-id", "plan_name": "free", "plan_id": "free-id"}),
+		}, nil).AnyTimes()
+
+		Convey("Should treat bindings from application manifests as declared", func() {
+			ioutil.WriteFile(stackPath, []byte(`services:
+  - name: db
+    offering: postgresql
+    plan: free
+applications:
+  - name: web
+    path: web.tar.gz
+    manifest: web.json
+`), 0644)
+
+			diff, err := actionsConfig.DiffStackFile(stackPath, "current target")
+
+			So(err, ShouldBeNil)
+			So(diff.Differences, ShouldBeEmpty)
+		})
+
+		Convey("Should compare envs of applications from manifests merged with stack file", func() {
+			ioutil.WriteFile(stackPath, []byte(`services:
+  - name: db
+    offering: postgresql
+    plan: free
+applications:
+  - name: web
+    path: web.tar.gz
+    manifest: web.json
+    envs:
+      LOG_LEVEL: debug
+`), 0644)
+
+			diff, err := actionsConfig.DiffStackFile(stackPath, "current target")
+
+			So(err, ShouldBeNil)
+			So(diff.Differences, ShouldResemble, []StackDifference{
+				{Operation: StackOperationUpdate, Kind: "application", Name: "web", Details: `env LOG_LEVEL "info" -> "debug"`},
+			})
+		})
+
+		Reset(func() {
+			os.RemoveAll(tempDir)
+			mockCtrl.Finish()
+		})
+	})
+}
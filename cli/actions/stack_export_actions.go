@@ -0,0 +1,155 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiServiceModels "github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
+	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
+)
+
+// stackExportHeader is printed before exported yaml stack. Sources of applications cannot be downloaded
+// and exposure of service instances is not reported by target, so both have to be completed by hand.
+const stackExportHeader = `# Stack exported from TAP target, recreate it with: tap apply -f <this file>
+# Application paths point to directories named after applications - put sources there before applying.
+# Urls of applications are assigned by TAP on push, they are exported to be compared by "tap diff".
+# Exposure of services is not exported - run "tap service expose" for them after applying if needed.
+`
+
+// systemMetadataKeys are set by TAP on service instances, so they are not envs given on creation
+var systemMetadataKeys = []string{
+	catalogModels.OFFERING_PLAN_ID,
+	catalogModels.LAST_STATE_CHANGE_REASON,
+	catalogModels.BROKER_TEMPLATE_ID,
+	catalogModels.APPLICATION_IMAGE_ADDRESS,
+}
+
+// ExportStack prints stack describing service instances, applications and bindings existing on target,
+// as yaml or as json when json output format is chosen
+func (a *ActionsConfig) ExportStack() error {
+	stack, err := a.exportStack()
+	if err != nil {
+		return err
+	}
+
+	if printer.GetOutputFormat() == printer.OutputFormatJSON {
+		printer.PrintFormattedJSON(stack)
+		fmt.Println()
+		return nil
+	}
+	fmt.Print(stackExportHeader)
+	printer.PrintYAML(stack)
+	return nil
+}
+
+func (a *ActionsConfig) exportStack() (manifest.Stack, error) {
+	env, err := a.readEnvironment()
+	if err != nil {
+		return manifest.Stack{}, err
+	}
+	return a.exportEnvironment(env)
+}
+
+func (a *ActionsConfig) exportEnvironment(env *environment) (manifest.Stack, error) {
+	stack := manifest.Stack{
+		Services:     []manifest.StackService{},
+		Applications: []manifest.StackApplication{},
+	}
+
+	// offerings are fetched once, only when some instance does not carry offering and plan names
+	var offerings []apiServiceModels.Offering
+	for _, name := range sortedKeys(env.services) {
+		service, err := a.exportStackService(env.services[name], &offerings)
+		if err != nil {
+			return stack, fmt.Errorf("cannot export service %s: %v", name, err)
+		}
+		stack.Services = append(stack.Services, service)
+	}
+	for _, name := range sortedKeys(env.applications) {
+		stack.Applications = append(stack.Applications, exportStackApplication(env.applications[name]))
+	}
+	stack.Bindings = env.bindings
+	return stack, nil
+}
+
+func (a *ActionsConfig) exportStackService(instance apiServiceModels.ServiceInstance,
+	offerings *[]apiServiceModels.Offering) (manifest.StackService, error) {
+
+	offeringName, planName := instance.ServiceName, instance.ServicePlanName
+	if offeringName == "" || planName == "" {
+		if *offerings == nil {
+			fetched, err := a.ApiService.GetOfferings()
+			if err != nil {
+				return manifest.StackService{}, err
+			}
+			*offerings = append([]apiServiceModels.Offering{}, fetched...)
+		}
+		planID := catalogModels.GetValueFromMetadata(instance.Metadata, catalogModels.OFFERING_PLAN_ID)
+		var err error
+		offeringName, planName, err = converter.FindOfferingAndPlanNames(*offerings, instance.OfferingId, planID)
+		if err != nil {
+			return manifest.StackService{}, err
+		}
+	}
+
+	return manifest.StackService{
+		Name:     instance.Name,
+		Offering: offeringName,
+		Plan:     planName,
+		Envs:     exportEnvs(instance.Metadata),
+	}, nil
+}
+
+func exportStackApplication(instance apiServiceModels.ApplicationInstance) manifest.StackApplication {
+	replicas := instance.Replication
+	application := manifest.StackApplication{
+		Name:     instance.Name,
+		Path:     instance.Name,
+		Replicas: &replicas,
+		Envs:     exportEnvs(instance.Metadata),
+	}
+	if len(instance.Urls) > 0 {
+		application.Urls = append([]string{}, instance.Urls...)
+		sort.Strings(application.Urls)
+	}
+	return application
+}
+
+// exportEnvs returns metadata of instance which is not set by TAP, nil when there is none
+func exportEnvs(metadata []catalogModels.Metadata) map[string]string {
+	var envs map[string]string
+	for _, entry := range metadata {
+		if isSystemMetadata(entry.Id) {
+			continue
+		}
+		if envs == nil {
+			envs = make(map[string]string)
+		}
+		envs[entry.Id] = entry.Value
+	}
+	return envs
+}
+
+func isSystemMetadata(key string) bool {
+	return contains(systemMetadataKeys, key) || strings.HasPrefix(key, catalogModels.BROKER_OFFERING_PREFIX)
+}
@@ -0,0 +1,142 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestExportStack(t *testing.T) {
+	Convey("Test exporting stack of target", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		offerings := []models.Offering{
+			test.NewFakeOffering(map[string]string{"name": "postgresql", "offering_id": "postgresql-id", "plan_name": "free", "plan_id": "free-id"}),
+		}
+
+		apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+			{Id: "db-id", Name: "db", OfferingId: "postgresql-id", Metadata: []catalogModels.Metadata{
+				{Id: catalogModels.OFFERING_PLAN_ID, Value: "free-id"},
+				{Id: catalogModels.LAST_STATE_CHANGE_REASON, Value: "started"},
+				{Id: catalogModels.BROKER_OFFERING_PREFIX + "postgresql-id", Value: "postgresql"},
+				{Id: "MAX_CONNECTIONS", Value: "10"},
+			}},
+		}, nil)
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{
+			{Id: "web-id", Name: "web", Replication: 2, Bindings: []catalogModels.InstanceBindings{{Id: "db-id"}},
+				Urls: []string{"web.example.org", "web.example.com"}, Metadata: []catalogModels.Metadata{
+					{Id: catalogModels.APPLICATION_IMAGE_ADDRESS, Value: "registry/web"},
+					{Id: "LOG_LEVEL", Value: "debug"},
+				}},
+		}, nil)
+
+		Convey("Should describe instances by names with envs, replicas, urls and bindings", func() {
+			apiMock.EXPECT().GetOfferings().Return(offerings, nil)
+
+			stack, err := actionsConfig.exportStack()
+
+			replicas := 2
+			So(err, ShouldBeNil)
+			So(stack, ShouldResemble, manifest.Stack{
+				Services: []manifest.StackService{{Name: "db", Offering: "postgresql", Plan: "free",
+					Envs: map[string]string{"MAX_CONNECTIONS": "10"}}},
+				Applications: []manifest.StackApplication{{Name: "web", Path: "web", Replicas: &replicas,
+					Envs: map[string]string{"LOG_LEVEL": "debug"}, Urls: []string{"web.example.com", "web.example.org"}}},
+				Bindings: []manifest.StackBinding{{Src: "db", Dst: "web"}},
+			})
+		})
+
+		Convey("Should fail when GetOfferings returns error", func() {
+			apiMock.EXPECT().GetOfferings().Return(nil, errors.New("offerings unavailable"))
+
+			_, err := actionsConfig.exportStack()
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "cannot export service db: offerings unavailable")
+		})
+
+		Convey("Should fail when offering of service cannot be found", func() {
+			apiMock.EXPECT().GetOfferings().Return([]models.Offering{
+				test.NewFakeOffering(map[string]string{"name": "mysql", "offering_id": "mysql-id", "plan_name": "free", "plan_id": "free-id"}),
+			}, nil)
+
+			_, err := actionsConfig.exportStack()
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "cannot export service db: cannot find service with id: 'postgresql-id'")
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestExportStackServices(t *testing.T) {
+	Convey("Test exporting offerings and plans of services", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{}, nil)
+		planMetadata := []catalogModels.Metadata{{Id: catalogModels.OFFERING_PLAN_ID, Value: "free-id"}}
+
+		Convey("Should use offering and plan names of instances", func() {
+			apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+				{Id: "cache-id", Name: "cache", ServiceName: "redis", ServicePlanName: "small"},
+				{Id: "db-id", Name: "db", ServiceName: "postgresql", ServicePlanName: "free"},
+			}, nil)
+
+			stack, err := actionsConfig.exportStack()
+
+			So(err, ShouldBeNil)
+			So(stack.Services, ShouldResemble, []manifest.StackService{
+				{Name: "cache", Offering: "redis", Plan: "small"},
+				{Name: "db", Offering: "postgresql", Plan: "free"},
+			})
+		})
+
+		Convey("Should fetch offerings once for instances without names", func() {
+			apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{
+				{Id: "db-id", Name: "db", OfferingId: "postgresql-id", Metadata: planMetadata},
+				{Id: "db2-id", Name: "db2", OfferingId: "postgresql-id", Metadata: planMetadata},
+			}, nil)
+			apiMock.EXPECT().GetOfferings().Return([]models.Offering{
+				test.NewFakeOffering(map[string]string{"name": "postgresql", "offering_id": "postgresql-id", "plan_name": "free", "plan_id": "free-id"}),
+			}, nil).Times(1)
+
+			stack, err := actionsConfig.exportStack()
+
+			So(err, ShouldBeNil)
+			So(stack.Services, ShouldResemble, []manifest.StackService{
+				{Name: "db", Offering: "postgresql", Plan: "free"},
+				{Name: "db2", Offering: "postgresql", Plan: "free"},
+			})
+		})
+
+		Reset(func() {
+			mockCtrl.Finish()
+		})
+	})
+}
@@ -38,16 +38,15 @@ func (a *ActionsConfig) ListUsers() error {
 		fmt.Println("Listing users failed")
 		return err
 	}
-	printUsers(users)
-	return nil
+	return printUsers(users)
 }
 
-func printUsers(users []userManagement.UaaUser) {
+func printUsers(users []userManagement.UaaUser) error {
 	printableUsers := []printer.Printable{}
 	for _, user := range users {
 		printableUsers = append(printableUsers, printer.PrintableUser{UaaUser: user})
 	}
-	printer.PrintTable(printableUsers)
+	return printer.PrintList(printableUsers)
 }
 
 func (a *ActionsConfig) DeleteUser(email string) error {
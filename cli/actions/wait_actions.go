@@ -0,0 +1,292 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+)
+
+const (
+	DefaultWaitTimeout = 5 * time.Minute
+	waitPollInterval   = 2 * time.Second
+)
+
+// InstanceStatus is state of application or service instance observed while waiting
+type InstanceStatus struct {
+	Exists     bool
+	State      catalogModels.InstanceState
+	ImageState catalogModels.ImageState
+	// RunningInstances is number of running replicas of application
+	RunningInstances int
+	// ChangedOn is time of last update of instance, taken from its audit trail
+	ChangedOn int64
+	// Reason is value of LAST_STATE_CHANGE_REASON metadata
+	Reason string
+	// Bindings are ids of instances bound to this one
+	Bindings []string
+}
+
+// changedFrom tells if instance has moved away from initial status
+func (s InstanceStatus) changedFrom(initial InstanceStatus) bool {
+	return s.Exists != initial.Exists || s.State != initial.State || s.ChangedOn != initial.ChangedOn ||
+		s.RunningInstances != initial.RunningInstances
+}
+
+func (s InstanceStatus) String() string {
+	if !s.Exists {
+		return "not existing"
+	}
+	if s.ImageState != "" {
+		return fmt.Sprintf("state %s, image %s", s.State, s.ImageState)
+	}
+	return "state " + string(s.State)
+}
+
+// WaitCondition describes state which instance is expected to reach
+type WaitCondition struct {
+	Description string
+	IsMet       func(status InstanceStatus) bool
+}
+
+func StateCondition(state catalogModels.InstanceState) WaitCondition {
+	return WaitCondition{
+		Description: "state " + string(state),
+		IsMet: func(status InstanceStatus) bool {
+			return status.Exists && status.State == state
+		},
+	}
+}
+
+func ImageStateCondition(imageState catalogModels.ImageState) WaitCondition {
+	return WaitCondition{
+		Description: "image state " + string(imageState),
+		IsMet: func(status InstanceStatus) bool {
+			return status.Exists && status.ImageState == imageState
+		},
+	}
+}
+
+// ReplicasCondition is met when instance is running with given number of replicas
+func ReplicasCondition(replicas int) WaitCondition {
+	return WaitCondition{
+		Description: fmt.Sprintf("%d running replicas", replicas),
+		IsMet: func(status InstanceStatus) bool {
+			return status.Exists && status.State == catalogModels.InstanceStateRunning && status.RunningInstances == replicas
+		},
+	}
+}
+
+// UnboundCondition is met when instance is not bound to instance with given id anymore, or does not exist
+func UnboundCondition(srcID string) WaitCondition {
+	return WaitCondition{
+		Description: "unbinding",
+		IsMet: func(status InstanceStatus) bool {
+			return !status.Exists || !contains(status.Bindings, srcID)
+		},
+	}
+}
+
+// DeletedCondition is met when instance does not exist
+func DeletedCondition() WaitCondition {
+	return WaitCondition{
+		Description: "deletion",
+		IsMet: func(status InstanceStatus) bool {
+			return !status.Exists
+		},
+	}
+}
+
+var (
+	instanceStates = []catalogModels.InstanceState{
+		catalogModels.InstanceStateRequested, catalogModels.InstanceStateDeploying, catalogModels.InstanceStateFailure,
+		catalogModels.InstanceStateStopped, catalogModels.InstanceStateStartReq, catalogModels.InstanceStateStarting,
+		catalogModels.InstanceStateRunning, catalogModels.InstanceStateReconfiguration, catalogModels.InstanceStateStopReq,
+		catalogModels.InstanceStateStopping, catalogModels.InstanceStateDestroyReq, catalogModels.InstanceStateDestroying,
+		catalogModels.InstanceStateUnavailable,
+	}
+	imageStates = []catalogModels.ImageState{
+		catalogModels.ImageStateBuilding, catalogModels.ImageStateError, catalogModels.ImageStatePending,
+		catalogModels.ImageStateReady, catalogModels.ImageStateRequested, catalogModels.ImageStateRemoving,
+	}
+)
+
+// ParseWaitCondition parses condition in one of forms: state=RUNNING, image-state=READY, replicas=3 or deleted
+func ParseWaitCondition(expression string) (WaitCondition, error) {
+	if expression == "deleted" {
+		return DeletedCondition(), nil
+	}
+
+	parts := strings.SplitN(expression, "=", 2)
+	if len(parts) != 2 {
+		return WaitCondition{}, fmt.Errorf("unsupported condition %q, use state=STATE, image-state=STATE, replicas=NUMBER or deleted", expression)
+	}
+	value := strings.ToUpper(strings.TrimSpace(parts[1]))
+	switch strings.TrimSpace(parts[0]) {
+	case "state":
+		for _, state := range instanceStates {
+			if string(state) == value {
+				return StateCondition(state), nil
+			}
+		}
+		return WaitCondition{}, fmt.Errorf("unknown instance state %q, expected one of: %v", parts[1], instanceStates)
+	case "image-state":
+		for _, state := range imageStates {
+			if string(state) == value {
+				return ImageStateCondition(state), nil
+			}
+		}
+		return WaitCondition{}, fmt.Errorf("unknown image state %q, expected one of: %v", parts[1], imageStates)
+	case "replicas":
+		replicas, err := strconv.Atoi(value)
+		if err != nil || replicas < 0 {
+			return WaitCondition{}, fmt.Errorf("number of replicas should be a non-negative integer, got %q", parts[1])
+		}
+		return ReplicasCondition(replicas), nil
+	}
+	return WaitCondition{}, fmt.Errorf("unsupported condition %q, use state=STATE, image-state=STATE, replicas=NUMBER or deleted", expression)
+}
+
+// InstanceFailureError is returned when instance fails while it is waited for
+type InstanceFailureError struct {
+	InstanceName string
+	Status       InstanceStatus
+}
+
+func (e *InstanceFailureError) Error() string {
+	message := fmt.Sprintf("instance %q failed (%s)", e.InstanceName, e.Status)
+	if e.Status.Reason != "" {
+		message += ": " + e.Status.Reason
+	}
+	return message
+}
+
+// WaitTimeoutError is returned when instance does not reach expected state in time
+type WaitTimeoutError struct {
+	InstanceName string
+	Condition    WaitCondition
+	Timeout      time.Duration
+	Status       InstanceStatus
+}
+
+func (e *WaitTimeoutError) Error() string {
+	return fmt.Sprintf("instance %q did not reach %s in %v, it is %s", e.InstanceName, e.Condition.Description, e.Timeout, e.Status)
+}
+
+// WaitForInstance polls instance until it meets condition. It fails fast when instance
+// (or its image) fails, and after timeout.
+func (a *ActionsConfig) WaitForInstance(instanceType catalogModels.InstanceType, instanceName string,
+	condition WaitCondition, timeout time.Duration) error {
+
+	return a.waitForInstance(instanceType, instanceName, nil, condition, timeout)
+}
+
+// WaitForInstanceChange works like WaitForInstance for instance which had initial status before an operation.
+// Condition and failure are taken into account only after instance has moved away from that status, so that
+// state left from before the operation (e.g. RUNNING before restart) is not taken for its result.
+func (a *ActionsConfig) WaitForInstanceChange(instanceType catalogModels.InstanceType, instanceName string,
+	initial InstanceStatus, condition WaitCondition, timeout time.Duration) error {
+
+	return a.waitForInstance(instanceType, instanceName, &initial, condition, timeout)
+}
+
+func (a *ActionsConfig) waitForInstance(instanceType catalogModels.InstanceType, instanceName string,
+	initial *InstanceStatus, condition WaitCondition, timeout time.Duration) error {
+
+	fmt.Printf("Waiting up to %v for %q to reach %s\n", timeout, instanceName, condition.Description)
+	deadline := timeNow().Add(timeout)
+	moved := initial == nil
+	lastStatus := ""
+	for {
+		status, err := a.GetInstanceStatus(instanceType, instanceName)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			moved = status.changedFrom(*initial)
+		}
+
+		if status.String() != lastStatus {
+			lastStatus = status.String()
+			fmt.Printf("%s: %s\n", instanceName, lastStatus)
+		}
+		if moved && condition.IsMet(status) {
+			return nil
+		}
+		if moved && (status.State == catalogModels.InstanceStateFailure || status.ImageState == catalogModels.ImageStateError) {
+			return &InstanceFailureError{InstanceName: instanceName, Status: status}
+		}
+		if !timeNow().Before(deadline) {
+			return &WaitTimeoutError{InstanceName: instanceName, Condition: condition, Timeout: timeout, Status: status}
+		}
+		sleep(waitPollInterval)
+	}
+}
+
+// GetInstanceStatus finds instance on instances list by its name. Status of not existing instance is zero value.
+func (a *ActionsConfig) GetInstanceStatus(instanceType catalogModels.InstanceType, instanceName string) (InstanceStatus, error) {
+	if instanceType == converter.InstanceTypeBoth || instanceType == catalogModels.InstanceTypeService {
+		services, err := a.ApiService.ListServiceInstances()
+		if err != nil {
+			return InstanceStatus{}, fmt.Errorf("cannot fetch services list: %v", err)
+		}
+		for _, service := range services {
+			if service.Name == instanceName {
+				return InstanceStatus{
+					Exists:    true,
+					State:     service.State,
+					ChangedOn: service.AuditTrail.LastUpdatedOn,
+					Reason:    catalogModels.GetValueFromMetadata(service.Metadata, catalogModels.LAST_STATE_CHANGE_REASON),
+					Bindings:  bindingIDs(service.Bindings),
+				}, nil
+			}
+		}
+	}
+	if instanceType == converter.InstanceTypeBoth || instanceType == catalogModels.InstanceTypeApplication {
+		applications, err := a.ApiService.ListApplicationInstances()
+		if err != nil {
+			return InstanceStatus{}, fmt.Errorf("cannot fetch applications list: %v", err)
+		}
+		for _, application := range applications {
+			if application.Name == instanceName {
+				return InstanceStatus{
+					Exists:           true,
+					State:            application.State,
+					ImageState:       application.ImageState,
+					RunningInstances: application.RunningInstances,
+					ChangedOn:        application.AuditTrail.LastUpdatedOn,
+					Reason:           catalogModels.GetValueFromMetadata(application.Metadata, catalogModels.LAST_STATE_CHANGE_REASON),
+					Bindings:         bindingIDs(application.Bindings),
+				}, nil
+			}
+		}
+	}
+	return InstanceStatus{}, nil
+}
+
+func bindingIDs(bindings []catalogModels.InstanceBindings) []string {
+	ids := []string{}
+	for _, binding := range bindings {
+		ids = append(ids, binding.Id)
+	}
+	return ids
+}
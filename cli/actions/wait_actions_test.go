@@ -0,0 +1,231 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package actions
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/smartystreets/assertions"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+	"github.com/trustedanalytics-ng/tap-cli/cli/test"
+)
+
+func TestWaitForInstance(t *testing.T) {
+	Convey("Test waiting for instance state", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		now := fakeNow
+		timeNow = func() time.Time {
+			now = now.Add(time.Minute)
+			return now
+		}
+		sleep = func(time.Duration) {}
+
+		Convey("Should return when instance reaches expected state", func() {
+			gomock.InOrder(
+				apiMock.EXPECT().ListServiceInstances().Return(serviceWithState(catalogModels.InstanceStateDeploying, 1), nil),
+				apiMock.EXPECT().ListServiceInstances().Return(serviceWithState(catalogModels.InstanceStateRunning, 2), nil),
+			)
+
+			err := actionsConfig.WaitForInstance(catalogModels.InstanceTypeService, "db",
+				StateCondition(catalogModels.InstanceStateRunning), 5*time.Minute)
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Should fail fast with reason when instance fails", func() {
+			failed := serviceWithState(catalogModels.InstanceStateFailure, 1)
+			failed[0].Metadata = []catalogModels.Metadata{{Id: catalogModels.LAST_STATE_CHANGE_REASON, Value: "image not found"}}
+			apiMock.EXPECT().ListServiceInstances().Return(failed, nil).Times(1)
+
+			err := actionsConfig.WaitForInstance(catalogModels.InstanceTypeService, "db",
+				StateCondition(catalogModels.InstanceStateRunning), 5*time.Minute)
+
+			So(err, ShouldHaveSameTypeAs, &InstanceFailureError{})
+			So(err.Error(), assertions.ShouldContainSubstring, "image not found")
+		})
+
+		Convey("Should fail after timeout", func() {
+			apiMock.EXPECT().ListServiceInstances().Return(serviceWithState(catalogModels.InstanceStateDeploying, 1), nil).AnyTimes()
+
+			err := actionsConfig.WaitForInstance(catalogModels.InstanceTypeService, "db",
+				StateCondition(catalogModels.InstanceStateRunning), 3*time.Minute)
+
+			So(err, ShouldHaveSameTypeAs, &WaitTimeoutError{})
+			So(err.Error(), assertions.ShouldContainSubstring, "state DEPLOYING")
+		})
+
+		Reset(func() {
+			timeNow = func() time.Time { return fakeNow }
+			sleep = time.Sleep
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestWaitForInstanceChange(t *testing.T) {
+	Convey("Test waiting for instance after operation", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		sleep = func(time.Duration) {}
+
+		Convey("Should not take state from before restart for its result", func() {
+			running := applicationWithState(catalogModels.InstanceStateRunning, 1, 1)
+			gomock.InOrder(
+				apiMock.EXPECT().ListApplicationInstances().Return(running, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(running, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(applicationWithState(catalogModels.InstanceStateStopping, 1, 2), nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(applicationWithState(catalogModels.InstanceStateRunning, 1, 3), nil),
+			)
+
+			initial, err := actionsConfig.GetInstanceStatus(catalogModels.InstanceTypeApplication, "web")
+			So(err, ShouldBeNil)
+			stdout := test.CaptureStdout(func() {
+				err = actionsConfig.WaitForInstanceChange(catalogModels.InstanceTypeApplication, "web", initial,
+					StateCondition(catalogModels.InstanceStateRunning), 5*time.Minute)
+			})
+
+			So(err, ShouldBeNil)
+			So(stdout, ShouldContainSubstring, "web: state STOPPING")
+		})
+
+		Convey("Should not fail start on failure from before it", func() {
+			failed := serviceWithState(catalogModels.InstanceStateFailure, 1)
+			gomock.InOrder(
+				apiMock.EXPECT().ListServiceInstances().Return(failed, nil),
+				apiMock.EXPECT().ListServiceInstances().Return(failed, nil),
+				apiMock.EXPECT().ListServiceInstances().Return(serviceWithState(catalogModels.InstanceStateRunning, 2), nil),
+			)
+
+			initial, err := actionsConfig.GetInstanceStatus(catalogModels.InstanceTypeService, "db")
+			So(err, ShouldBeNil)
+			err = actionsConfig.WaitForInstanceChange(catalogModels.InstanceTypeService, "db", initial,
+				StateCondition(catalogModels.InstanceStateRunning), 5*time.Minute)
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Should not take stale replicas count from before scaling for its result", func() {
+			// two of three replicas are running, when application is scaled down to two
+			stale := applicationWithState(catalogModels.InstanceStateRunning, 2, 1)
+			gomock.InOrder(
+				apiMock.EXPECT().ListApplicationInstances().Return(stale, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(stale, nil),
+				apiMock.EXPECT().ListApplicationInstances().Return(applicationWithState(catalogModels.InstanceStateRunning, 2, 2), nil),
+			)
+
+			initial, err := actionsConfig.GetInstanceStatus(catalogModels.InstanceTypeApplication, "web")
+			So(err, ShouldBeNil)
+			err = actionsConfig.WaitForInstanceChange(catalogModels.InstanceTypeApplication, "web", initial,
+				ReplicasCondition(2), 5*time.Minute)
+
+			So(err, ShouldBeNil)
+		})
+
+		Reset(func() {
+			sleep = time.Sleep
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func TestParseWaitCondition(t *testing.T) {
+	Convey("Test parsing wait conditions", t, func() {
+		running := InstanceStatus{Exists: true, State: catalogModels.InstanceStateRunning, RunningInstances: 3}
+
+		Convey("Should parse state, image state, replicas and deletion conditions", func() {
+			condition, err := ParseWaitCondition("state=running")
+			So(err, ShouldBeNil)
+			So(condition.IsMet(running), ShouldBeTrue)
+
+			condition, err = ParseWaitCondition("image-state=READY")
+			So(err, ShouldBeNil)
+			So(condition.IsMet(InstanceStatus{Exists: true, ImageState: catalogModels.ImageStateReady}), ShouldBeTrue)
+
+			condition, err = ParseWaitCondition("replicas=3")
+			So(err, ShouldBeNil)
+			So(condition.IsMet(running), ShouldBeTrue)
+
+			condition, err = ParseWaitCondition("deleted")
+			So(err, ShouldBeNil)
+			So(condition.IsMet(running), ShouldBeFalse)
+			So(condition.IsMet(InstanceStatus{}), ShouldBeTrue)
+		})
+
+		Convey("Should reject unknown conditions and values", func() {
+			for _, expression := range []string{"running", "state=WORKING", "image-state=DONE", "replicas=-1", "size=3"} {
+				_, err := ParseWaitCondition(expression)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+func TestWaitForDeletedInstance(t *testing.T) {
+	Convey("Test waiting for instance deletion", t, func() {
+		actionsConfig, mockCtrl := setupActionsTest(t)
+		apiMock := actionsConfig.ApiService.(*api.MockTapApiServiceApi)
+		sleep = func(time.Duration) {}
+
+		Convey("Should return when instance is not found any more", func() {
+			gomock.InOrder(
+				apiMock.EXPECT().ListServiceInstances().Return(serviceWithState(catalogModels.InstanceStateDestroying, 1), nil),
+				apiMock.EXPECT().ListServiceInstances().Return([]models.ServiceInstance{}, nil),
+			)
+			apiMock.EXPECT().ListApplicationInstances().Return([]models.ApplicationInstance{}, nil).Times(1)
+
+			err := actionsConfig.WaitForInstance(converter.InstanceTypeBoth, "db", DeletedCondition(), time.Minute)
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Should fail instead of reporting deletion when instances cannot be listed", func() {
+			apiMock.EXPECT().ListServiceInstances().Return(nil, errors.New("unavailable")).Times(1)
+
+			err := actionsConfig.WaitForInstance(converter.InstanceTypeBoth, "db", DeletedCondition(), time.Minute)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), assertions.ShouldContainSubstring, "unavailable")
+		})
+
+		Reset(func() {
+			sleep = time.Sleep
+			mockCtrl.Finish()
+		})
+	})
+}
+
+func serviceWithState(state catalogModels.InstanceState, changedOn int64) []models.ServiceInstance {
+	return []models.ServiceInstance{{
+		Id: "service-id", Name: "db", State: state, AuditTrail: catalogModels.AuditTrail{LastUpdatedOn: changedOn},
+	}}
+}
+
+func applicationWithState(state catalogModels.InstanceState, runningInstances int, changedOn int64) []models.ApplicationInstance {
+	return []models.ApplicationInstance{{
+		Id: "app-id", Name: "web", State: state, RunningInstances: runningInstances,
+		AuditTrail: catalogModels.AuditTrail{LastUpdatedOn: changedOn},
+	}}
+}
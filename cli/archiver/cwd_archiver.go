@@ -27,33 +27,117 @@ import (
 	"strings"
 )
 
+const RunScriptName = "run.sh"
+
+// ArchiveEntry describes file added to application archive
+type ArchiveEntry struct {
+	Path  string
+	Size  int64
+	Mode  os.FileMode
+	IsDir bool
+}
+
+// CreateApplicationArchive compresses folder into temporary file and returns its path
 func CreateApplicationArchive(folder string) (string, error) {
+	return createApplicationArchive(folder, printAddedEntry)
+}
+
+// CreateApplicationArchiveQuietly works as CreateApplicationArchive, without printing added files
+func CreateApplicationArchiveQuietly(folder string) (string, error) {
+	return createApplicationArchive(folder, nil)
+}
+
+func createApplicationArchive(folder string, onEntryAdded func(ArchiveEntry)) (string, error) {
 	tarball, err := ioutil.TempFile(os.TempDir(), "blob")
 	if err != nil {
 		fmt.Println(err)
 		return "", err
 	}
 	defer tarball.Close()
-	gz := gzip.NewWriter(tarball)
-	defer gz.Close()
+
+	err = WriteApplicationArchive(folder, tarball, onEntryAdded)
+	if err != nil {
+		os.Remove(tarball.Name())
+		return "", err
+	}
+	return tarball.Name(), nil
+}
+
+// WriteApplicationArchive writes folder compressed to gzipped tar into writer,
+// calling onEntryAdded (when given) for each archived file
+func WriteApplicationArchive(folder string, writer io.Writer, onEntryAdded func(ArchiveEntry)) error {
+	if err := CheckRunScript(folder); err != nil {
+		return err
+	}
+
+	ignoreRules, err := LoadIgnoreRules(folder)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	gz := gzip.NewWriter(writer)
 	tw := tar.NewWriter(gz)
-	defer tw.Close()
 
-	if _, err := os.Stat(filepath.Join(folder, "run.sh")); os.IsNotExist(err) {
+	err = filepath.Walk(folder, walkAndCompress(folder, tw, ignoreRules, onEntryAdded))
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// CheckRunScript checks that folder contains script starting application
+func CheckRunScript(folder string) error {
+	if _, err := os.Stat(filepath.Join(folder, RunScriptName)); os.IsNotExist(err) {
 		fmt.Println("run.sh does not exist")
 		fmt.Println("Create a script with commands how to install required dependencies offline and run your application.")
-		return "", err
+		return err
 	}
+	return nil
+}
 
-	err = filepath.Walk(folder, walkAndCompress(folder, tw))
+// ListArchive returns entries of gzipped tar archive
+func ListArchive(archivePath string) ([]ArchiveEntry, error) {
+	file, err := os.Open(archivePath)
 	if err != nil {
-		fmt.Println(err)
-		return "", err
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	entries := []ArchiveEntry{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ArchiveEntry{
+			Path:  strings.TrimPrefix(header.Name, "./"),
+			Size:  header.Size,
+			Mode:  header.FileInfo().Mode(),
+			IsDir: header.FileInfo().IsDir(),
+		})
 	}
-	return tarball.Name(), nil
 }
 
-func walkAndCompress(baseDir string, tw *tar.Writer) filepath.WalkFunc {
+func printAddedEntry(entry ArchiveEntry) {
+	fmt.Printf("Added to archive: %v\n", entry.Path)
+}
+
+func walkAndCompress(baseDir string, tw *tar.Writer, ignoreRules *IgnoreRules, onEntryAdded func(ArchiveEntry)) filepath.WalkFunc {
 	return func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -65,27 +149,40 @@ func walkAndCompress(baseDir string, tw *tar.Writer) filepath.WalkFunc {
 
 		relativePath := strings.TrimPrefix(path, baseDir+"/")
 
+		if ignoreRules.Ignores(relativePath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if (info.Mode() & os.ModeSymlink) == 0 {
-			err = saveFileToTar(tw, info, relativePath)
+			err = saveFileToTar(tw, info, path, relativePath)
 		} else {
-			err = saveSymlinkToTar(tw, info, relativePath)
+			err = saveSymlinkToTar(tw, info, path, relativePath)
 		}
 
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Added to archive: %v\n", relativePath)
+		if onEntryAdded != nil {
+			size := int64(0)
+			if info.Mode().IsRegular() {
+				size = info.Size()
+			}
+			onEntryAdded(ArchiveEntry{Path: relativePath, Size: size, Mode: info.Mode(), IsDir: info.IsDir()})
+		}
 		return nil
 	}
 }
 
-func saveFileToTar(tw *tar.Writer, info os.FileInfo, path string) error {
-	header, err := tar.FileInfoHeader(info, path)
+func saveFileToTar(tw *tar.Writer, info os.FileInfo, path, name string) error {
+	header, err := tar.FileInfoHeader(info, name)
 	if err != nil {
 		return err
 	}
-	header.Name = path
+	header.Name = name
 
 	err = tw.WriteHeader(header)
 	if err != nil {
@@ -106,7 +203,7 @@ func saveFileToTar(tw *tar.Writer, info os.FileInfo, path string) error {
 	return err
 }
 
-func saveSymlinkToTar(tw *tar.Writer, info os.FileInfo, path string) error {
+func saveSymlinkToTar(tw *tar.Writer, info os.FileInfo, path, name string) error {
 	symlink, err := os.Readlink(path)
 	if err != nil {
 		return err
@@ -116,7 +213,7 @@ func saveSymlinkToTar(tw *tar.Writer, info os.FileInfo, path string) error {
 	if err != nil {
 		return err
 	}
-	header.Name = path
+	header.Name = name
 
 	return tw.WriteHeader(header)
 }
@@ -0,0 +1,105 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestThatCreatedArchiveContainsNotIgnoredFiles(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archiver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	files := map[string]string{RunScriptName: "exec true", "app.py": "print(1)", ".git/HEAD": "ref", "debug.log": "log"}
+	for path, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(folder, path)), 0700)
+		if err = ioutil.WriteFile(filepath.Join(folder, path), []byte(content), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ioutil.WriteFile(filepath.Join(folder, IgnoreFileName), []byte("*.log"), 0600)
+
+	archivePath, err := CreateApplicationArchiveQuietly(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(archivePath)
+
+	entries, err := ListArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archived := map[string]int64{}
+	for _, entry := range entries {
+		archived[entry.Path] = entry.Size
+	}
+	expected := map[string]int64{RunScriptName: 9, "app.py": 8}
+	if !reflect.DeepEqual(archived, expected) {
+		t.Errorf("archived files: %v, expected: %v", archived, expected)
+	}
+}
+
+func TestThatArchiveWithoutRunScriptIsNotCreated(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archiver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	if _, err = CreateApplicationArchiveQuietly(folder); err == nil {
+		t.Error("archive creation should fail when there is no run.sh")
+	}
+}
+
+func TestThatArchiveOfOtherThanCurrentFolderContainsSymlinks(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archiver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	ioutil.WriteFile(filepath.Join(folder, RunScriptName), []byte("exec true"), 0700)
+	if err = os.Symlink(RunScriptName, filepath.Join(folder, "start.sh")); err != nil {
+		t.Fatal(err)
+	}
+
+	archivePath, err := CreateApplicationArchiveQuietly(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(archivePath)
+
+	entries, err := ListArchive(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := []string{}
+	for _, entry := range entries {
+		archived = append(archived, entry.Path)
+	}
+	if !reflect.DeepEqual(archived, []string{RunScriptName, "start.sh"}) {
+		t.Errorf("archived files: %v", archived)
+	}
+}
@@ -0,0 +1,184 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const IgnoreFileName = ".tapignore"
+
+// DefaultIgnorePatterns are applied before patterns from .tapignore file,
+// so each of them can be overridden there with negated pattern, e.g. "!.env"
+var DefaultIgnorePatterns = []string{
+	IgnoreFileName,
+	".git/",
+	".svn/",
+	".hg/",
+	".DS_Store",
+	".idea/",
+	"**/node_modules/.cache/",
+	"__pycache__/",
+	"*.pyc",
+	".venv/",
+	"venv/",
+	".env",
+}
+
+type ignorePattern struct {
+	regexp  *regexp.Regexp
+	negated bool
+	dirOnly bool
+}
+
+// IgnoreRules decide which files are left out of application archive. Patterns have gitignore syntax:
+// negation with "!", directory-only patterns ending with "/", anchoring with "/" and "**" globs.
+type IgnoreRules struct {
+	patterns []ignorePattern
+}
+
+// LoadIgnoreRules returns default rules extended with patterns from .tapignore file in folder, if it exists.
+// Malformed pattern is reported with its line number.
+func LoadIgnoreRules(folder string) (*IgnoreRules, error) {
+	rules, err := NewIgnoreRules(DefaultIgnorePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(folder, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return rules, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if err = rules.AddPattern(scanner.Text()); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", IgnoreFileName, lineNumber, err)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+func NewIgnoreRules(patterns []string) (*IgnoreRules, error) {
+	rules := &IgnoreRules{}
+	for _, pattern := range patterns {
+		if err := rules.AddPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// AddPattern adds single line of ignore file; blank lines and comments are skipped
+func (r *IgnoreRules) AddPattern(line string) error {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	pattern := ignorePattern{}
+	if strings.HasPrefix(line, "!") {
+		pattern.negated = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	// pattern with slash at the beginning or in the middle is relative to archived folder,
+	// otherwise it matches on any level
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expression := globToRegexp(line)
+	if !anchored && !strings.HasPrefix(expression, "(?:.*/)?") {
+		expression = "(?:.*/)?" + expression
+	}
+
+	compiled, err := regexp.Compile("^" + expression + "$")
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q", line)
+	}
+	pattern.regexp = compiled
+	r.patterns = append(r.patterns, pattern)
+	return nil
+}
+
+// Ignores checks if path relative to archived folder should be left out. The last matching pattern decides.
+func (r *IgnoreRules) Ignores(relativePath string, isDir bool) bool {
+	relativePath = filepath.ToSlash(relativePath)
+	ignored := false
+	for _, pattern := range r.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.regexp.MatchString(relativePath) {
+			ignored = !pattern.negated
+		}
+	}
+	return ignored
+}
+
+func globToRegexp(glob string) string {
+	var result bytes.Buffer
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			result.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && (i == 0 || glob[i-1] == '/'):
+			result.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			result.WriteString("[^/]*")
+		case glob[i] == '?':
+			result.WriteString("[^/]")
+		case glob[i] == '[':
+			end := strings.Index(glob[i+1:], "]")
+			if end == -1 {
+				result.WriteString(regexp.QuoteMeta(glob[i:]))
+				return result.String()
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			result.WriteString("[" + strings.Replace(class, "\\", "\\\\", -1) + "]")
+			i += end + 1
+		case glob[i] == '\\' && i+1 < len(glob):
+			i++
+			result.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			result.WriteString(regexp.QuoteMeta(string(glob[i])))
+		}
+	}
+	return result.String()
+}
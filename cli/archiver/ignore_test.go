@@ -0,0 +1,108 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestThatIgnoreRules_matchGitignorePatterns(t *testing.T) {
+	testCases := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		ignored  bool
+	}{
+		{[]string{"*.log"}, "app.log", false, true},
+		{[]string{"*.log"}, "logs/app.log", false, true},
+		{[]string{"*.log"}, "app.go", false, false},
+		{[]string{"build/"}, "build", true, true},
+		{[]string{"build/"}, "build", false, false},
+		{[]string{"build/"}, "src/build", true, true},
+		{[]string{"/build"}, "src/build", true, false},
+		{[]string{"docs/*.md"}, "docs/readme.md", false, true},
+		{[]string{"docs/*.md"}, "docs/api/readme.md", false, false},
+		{[]string{"docs/**/*.md"}, "docs/api/v1/readme.md", false, true},
+		{[]string{"docs/**/*.md"}, "docs/readme.md", false, true},
+		{[]string{"**/cache"}, "a/b/cache", true, true},
+		{[]string{"data/**"}, "data/models/big.bin", false, true},
+		{[]string{"data/**"}, "data", true, false},
+		{[]string{"file?.txt"}, "file1.txt", false, true},
+		{[]string{"file[0-9].txt"}, "filex.txt", false, false},
+		{[]string{"file[!0-9].txt"}, "filex.txt", false, true},
+		{[]string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{[]string{"!keep.log", "*.log"}, "keep.log", false, true},
+		{[]string{"# comment", "", "\\#hash"}, "#hash", false, true},
+		{[]string{"# comment"}, "# comment", false, false},
+	}
+
+	for _, tc := range testCases {
+		rules, err := NewIgnoreRules(tc.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ignored := rules.Ignores(tc.path, tc.isDir); ignored != tc.ignored {
+			t.Errorf("patterns %q for path %q (dir: %v) returned %v, expected %v", tc.patterns, tc.path, tc.isDir, ignored, tc.ignored)
+		}
+	}
+}
+
+func TestThatLoadIgnoreRules_allowsOverridingDefaults(t *testing.T) {
+	folder, err := ioutil.TempDir("", "tapignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	if err = ioutil.WriteFile(filepath.Join(folder, IgnoreFileName), []byte("!.env\n*.tmp\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadIgnoreRules(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectations := map[string]bool{".env": false, "cache.tmp": true, ".git": true, "run.sh": false}
+	for path, expected := range expectations {
+		if ignored := rules.Ignores(path, path == ".git"); ignored != expected {
+			t.Errorf("path %q ignored: %v, expected %v", path, ignored, expected)
+		}
+	}
+}
+
+func TestThatLoadIgnoreRules_reportsLineOfMalformedPattern(t *testing.T) {
+	folder, err := ioutil.TempDir("", "tapignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	if err = ioutil.WriteFile(filepath.Join(folder, IgnoreFileName), []byte("*.tmp\nfile[z-a].txt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadIgnoreRules(folder)
+
+	expected := IgnoreFileName + `:2: invalid pattern "file[z-a].txt"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("LoadIgnoreRules returned %v, expected %s", err, expected)
+	}
+}
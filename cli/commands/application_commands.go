@@ -19,12 +19,15 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli"
 
 	apiServiceClient "github.com/trustedanalytics-ng/tap-api-service/client"
 	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
+	"github.com/trustedanalytics-ng/tap-cli/cli/manifest"
 )
 
 func applicationCommand() TapCommand {
@@ -36,7 +39,12 @@ func applicationCommand() TapCommand {
 		Destination: &applicationName,
 	}
 
-	const manifestFileName = "manifest.json"
+	var manifestPath string
+	var manifestPathFlag = cli.StringFlag{
+		Name:        "manifest",
+		Usage:       "`path to manifest` (json or yaml) describing application, by default manifest.json, manifest.yaml or manifest.yml",
+		Destination: &manifestPath,
+	}
 
 	var archivePath string
 	var archivePathFlag = cli.StringFlag{
@@ -45,6 +53,20 @@ func applicationCommand() TapCommand {
 		Destination: &archivePath,
 	}
 
+	var saveArchivePath string
+	var saveArchivePathFlag = cli.StringFlag{
+		Name:        "save-archive",
+		Usage:       "`path` where archive created from current directory should be kept",
+		Destination: &saveArchivePath,
+	}
+
+	dryRun := false
+	var dryRunFlag = cli.BoolFlag{
+		Name:        "dry-run",
+		Usage:       "list archive content and validate manifest and run script without pushing application",
+		Destination: &dryRun,
+	}
+
 	var replicas int
 	var replicasFlag = cli.IntFlag{
 		Name:        "replicas",
@@ -59,6 +81,13 @@ func applicationCommand() TapCommand {
 		Destination: &confirmed,
 	}
 
+	cascade := false
+	var cascadeFlag = cli.BoolFlag{
+		Name:        "cascade",
+		Usage:       "unbind application from instances using it before deletion",
+		Destination: &cascade,
+	}
+
 	var timeout uint
 	var timeoutFlag = cli.UintFlag{
 		Name:        "timeout",
@@ -67,6 +96,16 @@ func applicationCommand() TapCommand {
 		Destination: &timeout,
 	}
 
+	var retries uint
+	var retriesFlag = cli.UintFlag{
+		Name:        "retries",
+		Usage:       "number of upload retries after network failure, none by default",
+		Destination: &retries,
+	}
+
+	wait := &waitOptions{}
+	var logOptions actions.LogOptions
+
 	var listApplicationsCommand = TapCommand{
 		Name:  "list",
 		Usage: "list applications",
@@ -87,33 +126,54 @@ func applicationCommand() TapCommand {
 	var pushApplicationCommand = TapCommand{
 		Name: "push",
 		Usage: "create application from compressed current directory (by default) or from indicated tar archive,\n" +
-			"\tmanifest should be in current working directory",
-		OptionalFlags: []cli.Flag{archivePathFlag, timeoutFlag},
+			"\tmanifest is read from current working directory, unless other path is given",
+		OptionalFlags: append([]cli.Flag{archivePathFlag, manifestPathFlag, timeoutFlag, retriesFlag, dryRunFlag,
+			saveArchivePathFlag}, wait.flags()...),
 		MainAction: func(c *cli.Context) error {
-			if _, err := os.Stat(manifestFileName); os.IsNotExist(err) {
-				return fmt.Errorf(manifestFileName + " does not exist: create one with metadata about your application")
+			if manifestPath == "" {
+				var found bool
+				if manifestPath, found = manifest.FindApplicationManifest(); !found {
+					return fmt.Errorf("none of %s exists: create one with metadata about your application",
+						strings.Join(manifest.ApplicationManifestFileNames, ", "))
+				}
+			} else if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+				return fmt.Errorf("%s does not exist: create one with metadata about your application", manifestPath)
+			}
+			if archivePath != "" && saveArchivePath != "" {
+				return cli.NewExitError("--save-archive can be used only when archive is created from current directory", 1)
+			}
+
+			if dryRun {
+				return newCredentialsService().DryRunPushApplication(archivePath, manifestPath, saveArchivePath)
 			}
 
 			clientOperationTimeout := time.Duration(timeout) * time.Minute
 
+			a := newOAuth2Service()
+			var app catalogModels.Application
+			var err error
 			if "" == archivePath {
-				return newOAuth2Service().CompressCwdAndPushAsApplication(clientOperationTimeout)
+				app, err = a.CompressCwdAndPushAsApplication(manifestPath, clientOperationTimeout, int(retries), saveArchivePath)
+			} else {
+				app, err = a.PushApplication(archivePath, manifestPath, clientOperationTimeout, int(retries))
 			}
-			return newOAuth2Service().PushApplication(archivePath, clientOperationTimeout)
+			return wait.waitForNew(a, err, catalogModels.InstanceTypeApplication, app.Name,
+				actions.ImageStateCondition(catalogModels.ImageStateReady))
 		},
 	}
 
 	var deleteApplicationCommand = TapCommand{
 		Name:          "delete",
-		Usage:         "delete application",
+		Usage:         "delete application, refusing when other instances use it unless --cascade is given",
 		RequiredFlags: []cli.Flag{applicationNameFlag},
-		OptionalFlags: []cli.Flag{confirmationFlag},
+		OptionalFlags: []cli.Flag{confirmationFlag, cascadeFlag},
 		MainAction: func(c *cli.Context) error {
-			if !confirmed {
-				err := removalConfirmationPrompt("application " + applicationName)
-				cli.HandleExitCoder(err)
+			a := newOAuth2Service()
+			plan, err := a.PlanInstanceDeletion(catalogModels.InstanceTypeApplication, applicationName)
+			if err != nil {
+				return err
 			}
-			return newOAuth2Service().DeleteApplication(applicationName)
+			return deleteWithDependents(a, plan, confirmed, cascade)
 		},
 	}
 
@@ -121,8 +181,12 @@ func applicationCommand() TapCommand {
 		Name:          "start",
 		Usage:         "start application",
 		RequiredFlags: []cli.Flag{applicationNameFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().StartApplication(applicationName)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeApplication, applicationName, actions.StateCondition(catalogModels.InstanceStateRunning), func() error {
+				return a.StartApplication(applicationName)
+			})
 		},
 	}
 
@@ -130,8 +194,12 @@ func applicationCommand() TapCommand {
 		Name:          "stop",
 		Usage:         "stop application",
 		RequiredFlags: []cli.Flag{applicationNameFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().StopApplication(applicationName)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeApplication, applicationName, actions.StateCondition(catalogModels.InstanceStateStopped), func() error {
+				return a.StopApplication(applicationName)
+			})
 		},
 	}
 
@@ -139,8 +207,12 @@ func applicationCommand() TapCommand {
 		Name:          "restart",
 		Usage:         "restart application",
 		RequiredFlags: []cli.Flag{applicationNameFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().RestartApplication(applicationName)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeApplication, applicationName, actions.StateCondition(catalogModels.InstanceStateRunning), func() error {
+				return a.RestartApplication(applicationName)
+			})
 		},
 	}
 
@@ -148,8 +220,12 @@ func applicationCommand() TapCommand {
 		Name:          "scale",
 		Usage:         "scale application",
 		RequiredFlags: []cli.Flag{applicationNameFlag, replicasFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().ScaleApplication(applicationName, replicas)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeApplication, applicationName, actions.ReplicasCondition(replicas), func() error {
+				return a.ScaleApplication(applicationName, replicas)
+			})
 		},
 	}
 
@@ -157,8 +233,9 @@ func applicationCommand() TapCommand {
 		Name:          "show",
 		Usage:         "show application logs",
 		RequiredFlags: []cli.Flag{applicationNameFlag},
+		OptionalFlags: logFlags(&logOptions),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().GetInstanceLogs(applicationName)
+			return newOAuth2Service().GetInstanceLogs(applicationName, logOptions)
 		},
 	}
 
@@ -41,9 +41,15 @@ func bindingCommands(instanceType catalogModels.InstanceType) TapCommand {
 	var isDst bool
 	var isDstFlag = cli.BoolFlag{
 		Name:        "is-dst",
-		Usage:       "Show bindings for which, current " + instanceTypeString + " is a destination",
+		Usage:       "show incoming bindings - instances which have current " + instanceTypeString + " among their bindings",
 		Destination: &isDst,
 	}
+	var all bool
+	var allFlag = cli.BoolFlag{
+		Name:        "all",
+		Usage:       "show both outgoing and incoming bindings",
+		Destination: &all,
+	}
 	var dstName string
 	var dstFlag = cli.StringFlag{
 		Name:        "dst-name",
@@ -59,11 +65,20 @@ func bindingCommands(instanceType catalogModels.InstanceType) TapCommand {
 
 	var listBindingCommand = TapCommand{
 		Name:          "list",
-		Usage:         "list bindings",
-		RequiredFlags: []cli.Flag{nameFlag, isDstFlag},
+		Usage:         "list outgoing bindings of " + instanceTypeString + ", incoming ones with --is-dst or both with --all",
+		RequiredFlags: []cli.Flag{nameFlag},
+		OptionalFlags: []cli.Flag{isDstFlag, allFlag},
 		MainAction: func(c *cli.Context) error {
+			direction := actions.BindingDirectionOutgoing
+			if isDst && all {
+				return cli.NewExitError("--is-dst and --all cannot be used together", 1)
+			} else if isDst {
+				direction = actions.BindingDirectionIncoming
+			} else if all {
+				direction = actions.BindingDirectionAll
+			}
 			return newOAuth2Service().GetInstanceBindings(
-				actions.BindableInstance{Name: name, Type: instanceType})
+				actions.BindableInstance{Name: name, Type: instanceType}, direction)
 		},
 	}
 
@@ -114,3 +129,35 @@ func binding(name, dstName, srcName string, instanceType catalogModels.InstanceT
 	}
 	return errors.New("dstName and srcName cannot be empty at the same time. Verification for alternative flags probably failed.")
 }
+
+// globalBindingCommand gathers binding commands concerning all instances of target
+func globalBindingCommand() TapCommand {
+	var format string
+	var formatFlag = cli.StringFlag{
+		Name:        "format",
+		Usage:       "`format` of graph, one of: ascii, dot, mermaid",
+		Value:       string(actions.BindingGraphFormatASCII),
+		Destination: &format,
+	}
+
+	var graphCommand = TapCommand{
+		Name:          "graph",
+		Usage:         "render bindings of all applications and services as dependency graph, highlighting failed instances",
+		OptionalFlags: []cli.Flag{formatFlag},
+		MainAction: func(c *cli.Context) error {
+			return newOAuth2Service().PrintBindingGraph(actions.BindingGraphFormat(format))
+		},
+	}
+
+	return TapCommand{
+		Name:  "binding",
+		Usage: "bindings of all instances",
+		MainAction: func(c *cli.Context) error {
+			cli.ShowCommandHelp(c, c.Command.Name)
+			return nil
+		},
+		Subcommands: []TapCommand{
+			graphCommand,
+		},
+	}
+}
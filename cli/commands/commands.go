@@ -18,15 +18,18 @@ package commands
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/urfave/cli"
+	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/trustedanalytics-ng/tap-api-service/client"
 	"github.com/trustedanalytics-ng/tap-cli/api"
 	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
+	"github.com/trustedanalytics-ng/tap-cli/cli/printer"
 	commonHttp "github.com/trustedanalytics-ng/tap-go-common/http"
 	"github.com/trustedanalytics-ng/tap-go-common/logger"
 )
@@ -40,19 +43,43 @@ const (
 	alternativeFlagMissingExitCode = 7
 	alternativeFlagTooManyExitCode = 8
 	flagTypeNotSupported           = 9
+	sessionExpiredExitCode         = 10
+	waitTimeoutExitCode            = 11
+	instanceFailureExitCode        = 12
+	driftDetectedExitCode          = 13
+	hasDependentsExitCode          = 14
 )
 
+const defaultTokenType = "bearer"
+const passphraseEnvVar = "TAP_CLI_PASSPHRASE"
+
 var loggerVerbosity string
+var targetName string
+var apiAddressOverride string
+var tokenOverride string
+var tokenTypeOverride string
+var promptedPassphrase string
+
+// responses of API service received while command runs, they tell if it failed because session expired
+var responses = &api.ResponseRecorder{}
 
 func GetCommands() []cli.Command {
 	defaultInfoCommand := TapInfoCommand()
 	return toCommands([]TapCommand{
 		loginCommand(),
+		logoutCommand(),
 		defaultInfoCommand,
+		targetCommand(),
 		offeringCommand(),
 		serviceCommand(),
 		applicationCommand(),
 		userCommand(),
+		globalBindingCommand(),
+		logsCommand(),
+		applyCommand(),
+		exportCommand(),
+		diffCommand(),
+		waitCommand(),
 	}, &defaultInfoCommand)
 }
 
@@ -65,9 +92,52 @@ func GetCommonFlags() []cli.Flag {
 			Destination: &loggerVerbosity,
 			Value:       DefaultLogLevel,
 		},
+		cli.StringFlag{
+			Name:  "output,o",
+			Usage: fmt.Sprintf("output `FORMAT` of list and info commands [%s]", printer.SupportedOutputFormatsString()),
+		},
+		cli.StringFlag{
+			Name:  "target",
+			Usage: "`name` of stored target to use instead of current one (for login: name to store target under)",
+		},
+		cli.StringFlag{
+			Name:   "home",
+			Usage:  "`directory` in which credentials are stored (default: $HOME/.tap-cli)",
+			EnvVar: api.CliHomeEnvVar,
+		},
+		cli.StringFlag{
+			Name:   "api",
+			Usage:  "TAP `API` to use together with --token instead of stored credentials",
+			EnvVar: "TAP_API",
+		},
+		cli.StringFlag{
+			Name:   "token",
+			Usage:  "OAuth2 access `token` to use instead of stored credentials",
+			EnvVar: "TAP_TOKEN",
+		},
+		cli.StringFlag{
+			Name:   "token-type",
+			Usage:  "`type` of token given in --token",
+			Value:  defaultTokenType,
+			EnvVar: "TAP_TOKEN_TYPE",
+		},
 	}
 }
 
+// withCommonFlags appends common flags, skipping those which command defines on its own (e.g. login --api)
+func withCommonFlags(flags []cli.Flag) []cli.Flag {
+	defined := make(map[string]bool)
+	for _, flag := range flags {
+		defined[flag.GetName()] = true
+	}
+	for _, flag := range GetCommonFlags() {
+		if !defined[flag.GetName()] {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
 func handleCommonFlags(c *cli.Context) error {
 	if loggerVerbosity == "" {
 		loggerVerbosity = c.GlobalString("verbosity")
@@ -78,9 +148,63 @@ func handleCommonFlags(c *cli.Context) error {
 	if err := commonHttp.SetLoggerLevel(loggerVerbosity); err != nil {
 		return err
 	}
+	if err := printer.SetOutputFormat(commonStringFlag(c, "output")); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if home := commonStringFlag(c, "home"); home != "" {
+		api.SetCliHome(home)
+	}
+	warning, err := api.CheckCredentialsFilePermissions()
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	if warning != "" {
+		fmt.Fprintln(os.Stderr, "Warning: "+warning)
+	}
+	targetName = commonStringFlag(c, "target")
+	apiAddressOverride = commonStringFlag(c, "api")
+	tokenOverride = commonStringFlag(c, "token")
+	tokenTypeOverride = commonStringFlag(c, "token-type")
 	return nil
 }
 
+// commonStringFlag returns value of common flag from the innermost command on which it was set.
+// Common flags are registered on every command level, so they cannot share Destination. Commands
+// defining flag of the same name on their own (e.g. login --api) are skipped.
+func commonStringFlag(c *cli.Context, name string) string {
+	for ; c != nil; c = c.Parent() {
+		if !definesCommonFlag(c, name) {
+			continue
+		}
+		if value := c.String(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// definesCommonFlag tells if command of context has common flag of given name, not its own flag
+// named the same way. Contexts of application and of commands with subcommands have no command,
+// their flags are flags of application.
+func definesCommonFlag(c *cli.Context, name string) bool {
+	flags := c.Command.Flags
+	if c.Command.Name == "" {
+		flags = c.App.Flags
+	}
+	for _, flag := range flags {
+		if flag.GetName() != name {
+			continue
+		}
+		for _, common := range GetCommonFlags() {
+			if stringFlag, isString := flag.(cli.StringFlag); isString && stringFlag == common {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
 func sumFlags(flags ...[]cli.Flag) []cli.Flag {
 	res := []cli.Flag{}
 	for _, flag := range flags {
@@ -95,14 +219,14 @@ func checkIfRequiredFlagExists(c *cli.Context, flag cli.Flag) (string, bool) {
 		if sFlag.Destination == nil {
 			printMissingDestinationForFlagError(sFlag.Name)
 		}
-		if c.IsSet(sFlag.Name) {
-			return sFlag.Name, true
+		if isFlagSet(c, sFlag.Name) {
+			return primaryFlagName(sFlag.Name), true
 		}
 		// checking for default
 		if sFlag.Value != "" {
-			return sFlag.Name, true
+			return primaryFlagName(sFlag.Name), true
 		}
-		return sFlag.Name, false
+		return primaryFlagName(sFlag.Name), false
 	}
 
 	bFlag, ok := flag.(cli.BoolFlag)
@@ -111,7 +235,7 @@ func checkIfRequiredFlagExists(c *cli.Context, flag cli.Flag) (string, bool) {
 			printMissingDestinationForFlagError(bFlag.Name)
 		}
 		// bool Flag cannot have default values
-		return bFlag.Name, c.IsSet(bFlag.Name)
+		return primaryFlagName(bFlag.Name), isFlagSet(c, bFlag.Name)
 	}
 
 	iFlag, ok := flag.(cli.IntFlag)
@@ -119,14 +243,22 @@ func checkIfRequiredFlagExists(c *cli.Context, flag cli.Flag) (string, bool) {
 		if iFlag.Destination == nil {
 			printMissingDestinationForFlagError(iFlag.Name)
 		}
-		if c.IsSet(iFlag.Name) {
-			return iFlag.Name, true
+		if isFlagSet(c, iFlag.Name) {
+			return primaryFlagName(iFlag.Name), true
 		}
 		// checking for default. Int Flag cannot by set to 0 by default.
 		if iFlag.Value != 0 {
-			return iFlag.Name, true
+			return primaryFlagName(iFlag.Name), true
 		}
-		return iFlag.Name, false
+		return primaryFlagName(iFlag.Name), false
+	}
+
+	ssFlag, ok := flag.(cli.StringSliceFlag)
+	if ok {
+		if ssFlag.Value == nil {
+			printMissingDestinationForFlagError(ssFlag.Name)
+		}
+		return primaryFlagName(ssFlag.Name), isFlagSet(c, ssFlag.Name)
 	}
 
 	printApplicationBugInfo("Flag type not supported.")
@@ -134,6 +266,20 @@ func checkIfRequiredFlagExists(c *cli.Context, flag cli.Flag) (string, bool) {
 	return "", false
 }
 
+// isFlagSet checks if flag was given under its name or any of its aliases, e.g. "file,f"
+func isFlagSet(c *cli.Context, name string) bool {
+	for _, alias := range strings.Split(name, ",") {
+		if c.IsSet(strings.TrimSpace(alias)) {
+			return true
+		}
+	}
+	return false
+}
+
+func primaryFlagName(name string) string {
+	return strings.TrimSpace(strings.Split(name, ",")[0])
+}
+
 func printMissingDestinationForFlagError(flagName string) {
 	printApplicationBugInfo(flagName + " Destination not set.")
 	cli.OsExiter(flagDestinationNil)
@@ -154,6 +300,17 @@ func validateArgs(c *cli.Context, mustCount int) *cli.ExitError {
 	return nil
 }
 
+func nameFromFlagOrArg(c *cli.Context, name *string) *cli.ExitError {
+	if *name != "" {
+		return nil
+	}
+	if c.NArg() != 1 {
+		return cli.NewExitError("name not specified: \n"+c.Command.Name+" <name> or "+c.Command.Name+" "+c.Command.ArgsUsage, 1)
+	}
+	*name = c.Args().First()
+	return nil
+}
+
 func validateAndSplitEnvFlags(envs cli.StringSlice) (map[string]string, *cli.ExitError) {
 	result := make(map[string]string)
 	for _, env := range envs {
@@ -169,17 +326,105 @@ func validateAndSplitEnvFlags(envs cli.StringSlice) (map[string]string, *cli.Exi
 }
 
 func removalConfirmationPrompt(resourceName string) error {
-	fmt.Printf("Are you sure you want to delete %s? [y/N]: ", resourceName)
-	text, _ := bufio.NewReader(os.Stdin).ReadString('\n')
-	text = strings.TrimSpace(strings.ToLower(text))
-	if text != "y" && text != "yes" {
+	if !confirmationPrompt(fmt.Sprintf("Are you sure you want to delete %s?", resourceName)) {
 		return cli.NewExitError("Canceled", -1)
 	}
 	return nil
 }
 
+// deleteWithDependents refuses to delete resource having dependents unless cascade is set. With cascade
+// dependents are listed in confirmation and unbound or deleted before the resource.
+func deleteWithDependents(a *actions.ActionsConfig, plan *actions.DeletionPlan, confirmed, cascade bool) error {
+	if plan.HasDependents() && !cascade {
+		fmt.Printf("Cannot delete %s, it has dependents:\n", plan.Resource)
+		plan.PrintDependents()
+		return cli.NewExitError("Unbind or delete them first, or use --cascade to do it together with deletion", hasDependentsExitCode)
+	}
+	if !confirmed {
+		if !plan.HasDependents() {
+			if err := removalConfirmationPrompt(plan.Resource); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("Dependents of %s:\n", plan.Resource)
+			plan.PrintDependents()
+			plan.Print()
+			if !confirmationPrompt(fmt.Sprintf("Are you sure you want to delete %s together with its dependents?", plan.Resource)) {
+				return cli.NewExitError("Canceled", -1)
+			}
+		}
+	}
+	return a.ApplyDeletionPlan(plan)
+}
+
+func confirmationPrompt(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	text, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	text = strings.TrimSpace(strings.ToLower(text))
+	return text == "y" || text == "yes"
+}
+
+func isInteractive() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func newCredentialsService() *actions.ActionsConfig {
+	return &actions.ActionsConfig{Config: api.Config{Target: targetName, Passphrase: passphrase}}
+}
+
+// passphrase used for stored tokens encryption is taken from environment or prompted for (once per command)
+func passphrase() (string, error) {
+	if promptedPassphrase != "" {
+		return promptedPassphrase, nil
+	}
+	if value := os.Getenv(passphraseEnvVar); value != "" {
+		return value, nil
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("passphrase for stored token is required, set it in %s environment variable", passphraseEnvVar)
+	}
+	promptedPassphrase = promptForSensitive("Passphrase")
+	return promptedPassphrase, nil
+}
+
+// credentialsOverride returns credentials given with --api and --token flags (or TAP_API and TAP_TOKEN
+// environment variables), nil if none of them is given.
+func credentialsOverride() (*api.Credentials, error) {
+	if apiAddressOverride == "" && tokenOverride == "" {
+		return nil, nil
+	}
+	if apiAddressOverride == "" || tokenOverride == "" {
+		return nil, errors.New("--api and --token (or TAP_API and TAP_TOKEN) have to be given together")
+	}
+	tokenType := tokenTypeOverride
+	if tokenType == "" {
+		tokenType = defaultTokenType
+	}
+	return &api.Credentials{
+		Address:   normalizeApiAddress(apiAddressOverride),
+		Token:     tokenOverride,
+		TokenType: tokenType,
+	}, nil
+}
+
 func newOAuth2Service() *actions.ActionsConfig {
-	a := &actions.ActionsConfig{Config: api.Config{}}
+	a := newCredentialsService()
+
+	override, err := credentialsOverride()
+	if err != nil {
+		panic(err.Error())
+	}
+	a.CredentialsOverride = override
+	return withOAuth2Connectors(a)
+}
+
+// newOAuth2ServiceForTarget uses stored credentials of given target, --api and --token flags are not applied
+func newOAuth2ServiceForTarget(target string) *actions.ActionsConfig {
+	return withOAuth2Connectors(&actions.ActionsConfig{Config: api.Config{Target: target, Passphrase: passphrase}})
+}
+
+func withOAuth2Connectors(a *actions.ActionsConfig) *actions.ActionsConfig {
+	a.WarnAboutSessionExpiry()
 
 	creds, err := a.GetCredentials()
 	if err != nil {
@@ -189,12 +434,24 @@ func newOAuth2Service() *actions.ActionsConfig {
 		panic(err.Error())
 	}
 
-	apiConnector, err := client.NewTapApiServiceApiWithOAuth2AndCustomSSLValidation(creds.Address, creds.TokenType, creds.Token, creds.SkipSSLValidation)
+	httpClient, err := api.NewHttpClient(creds.SkipSSLValidation, creds.CertificateFiles)
 	if err != nil {
 		panic(err.Error())
 	}
+	responses.Wrap(httpClient)
 
-	a.ApiService = apiConnector
+	a.ApiService = &client.TapApiServiceApiOAuth2Connector{
+		Address:   creds.Address,
+		TokenType: creds.TokenType,
+		Token:     creds.Token,
+		Client:    httpClient,
+	}
+	a.ApplicationUploader = &api.TapApplicationUploader{
+		Address:   creds.Address,
+		TokenType: creds.TokenType,
+		Token:     creds.Token,
+		Client:    httpClient,
+	}
 	return a
 }
 
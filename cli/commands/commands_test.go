@@ -17,13 +17,18 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/urfave/cli"
 
 	"github.com/trustedanalytics-ng/tap-api-service/client"
+	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
 	"github.com/trustedanalytics-ng/tap-cli/cli/test"
 )
 
@@ -73,28 +78,171 @@ func TestApiAndLoginServiceSetters(t *testing.T) {
 	})
 }
 
+func TestCredentialsOverride(t *testing.T) {
+	Convey("Test credentials override", t, func() {
+		Convey("Should use api and token instead of credentials file", func() {
+			test.DeleteTestCredentialsFile()
+			apiAddressOverride, tokenOverride, tokenTypeOverride = "myaddress.com/", "token", ""
+
+			creds, err := newOAuth2Service().GetCredentials()
+
+			So(err, ShouldBeNil)
+			So(creds.Address, ShouldEqual, "https://myaddress.com")
+			So(creds.Token, ShouldEqual, "token")
+			So(creds.TokenType, ShouldEqual, defaultTokenType)
+		})
+		Convey("Should fail when token is given without api", func() {
+			apiAddressOverride, tokenOverride, tokenTypeOverride = "", "token", "bearer"
+
+			So(func() {
+				newOAuth2Service()
+			}, ShouldPanicWith, "--api and --token (or TAP_API and TAP_TOKEN) have to be given together")
+		})
+
+		Reset(func() {
+			apiAddressOverride, tokenOverride, tokenTypeOverride = "", "", ""
+		})
+	})
+}
+
+func TestRunWithRelogin(t *testing.T) {
+	Convey("Test runWithRelogin", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/expired" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		client := &http.Client{}
+		responses.Wrap(client)
+		calls := 0
+
+		Convey("Should return action error unchanged when session did not expire", func() {
+			actionErr := errors.New("some error")
+
+			err := runWithRelogin(nil, func(c *cli.Context) error {
+				calls++
+				client.Get(server.URL)
+				return actionErr
+			})
+
+			So(err, ShouldEqual, actionErr)
+			So(calls, ShouldEqual, 1)
+		})
+		Convey("Should fail with dedicated exit code in non-interactive mode", func() {
+			err := runWithRelogin(nil, func(c *cli.Context) error {
+				calls++
+				client.Get(server.URL + "/expired")
+				return errors.New("request failed")
+			})
+
+			exitErr, ok := err.(*cli.ExitError)
+			So(ok, ShouldBeTrue)
+			So(exitErr.ExitCode(), ShouldEqual, sessionExpiredExitCode)
+			So(exitErr.Error(), ShouldContainSubstring, "session expired")
+			So(calls, ShouldEqual, 1)
+		})
+		Convey("Should not offer retry when action made changes before session expired", func() {
+			err := runWithRelogin(nil, func(c *cli.Context) error {
+				calls++
+				client.Post(server.URL, "application/json", nil)
+				client.Post(server.URL+"/expired", "application/json", nil)
+				return errors.New("request failed")
+			})
+
+			exitErr, ok := err.(*cli.ExitError)
+			So(ok, ShouldBeTrue)
+			So(exitErr.ExitCode(), ShouldEqual, sessionExpiredExitCode)
+			So(exitErr.Error(), ShouldContainSubstring, "interrupted after making changes")
+			So(calls, ShouldEqual, 1)
+		})
+
+		Reset(func() {
+			server.Close()
+		})
+	})
+}
+
+func TestWithCommonFlags(t *testing.T) {
+	Convey("withCommonFlags should not duplicate flags defined by command", t, func() {
+		apiFlag := cli.StringFlag{Name: "api", Usage: "command specific api"}
+
+		flags := withCommonFlags([]cli.Flag{apiFlag})
+
+		So(len(flags), ShouldEqual, len(GetCommonFlags()))
+		So(flags[0], ShouldResemble, apiFlag)
+	})
+}
+
+func TestCommonStringFlag(t *testing.T) {
+	Convey("Test reading common flags", t, func() {
+		var apiAddress string
+		app := cli.NewApp()
+		app.HideVersion = true
+		app.Flags = GetCommonFlags()
+		app.Commands = []cli.Command{{
+			Name:  "login",
+			Flags: withCommonFlags([]cli.Flag{cli.StringFlag{Name: "api", Usage: "login api"}}),
+			Action: func(c *cli.Context) error {
+				apiAddress = commonStringFlag(c, "api")
+				return nil
+			},
+		}}
+
+		Convey("Should not take value of flag defined by command on its own", func() {
+			app.Run([]string{"tap", "login", "--api", "http://login"})
+
+			So(apiAddress, ShouldBeEmpty)
+		})
+
+		Convey("Should take value of common flag given before command", func() {
+			app.Run([]string{"tap", "--api", "http://global", "login", "--api", "http://login"})
+
+			So(apiAddress, ShouldEqual, "http://global")
+		})
+
+		Convey("Should take value of common flag given to command with subcommands", func() {
+			app.Commands = append(app.Commands, cli.Command{
+				Name:  "service",
+				Flags: withCommonFlags(nil),
+				Subcommands: []cli.Command{{
+					Name:  "list",
+					Flags: withCommonFlags(nil),
+					Action: func(c *cli.Context) error {
+						apiAddress = commonStringFlag(c, "api")
+						return nil
+					},
+				}},
+			})
+
+			app.Run([]string{"tap", "service", "--api", "http://service", "list"})
+
+			So(apiAddress, ShouldEqual, "http://service")
+		})
+	})
+}
+
 func TestNewBasicAuthService(t *testing.T) {
 	Convey("Should trim ending slash if provided", t, func() {
-		basicAuth := newBasicAuthService("myaddress.com/", "user", "password", false)
+		basicAuth := newBasicAuthService("myaddress.com/", "user", "password", false, api.CertificateFiles{})
 		basicCreds := basicAuth.ApiServiceLogin.(*client.TapApiServiceApiBasicAuthConnector)
 
 		So(basicCreds.Address, ShouldEqual, "https://myaddress.com")
 	})
 	Convey("Should add https address if address not provided", t, func() {
-		basicAuth := newBasicAuthService("myaddress.com", "user", "password", false)
+		basicAuth := newBasicAuthService("myaddress.com", "user", "password", false, api.CertificateFiles{})
 		basicCreds := basicAuth.ApiServiceLogin.(*client.TapApiServiceApiBasicAuthConnector)
 
 		So(basicCreds.Address, ShouldEqual, "https://myaddress.com")
 	})
 	Convey("Should not add https", t, func() {
 		Convey("when there is http:// ", func() {
-			basicAuth := newBasicAuthService("http://myaddress.com", "user", "password", false)
+			basicAuth := newBasicAuthService("http://myaddress.com", "user", "password", false, api.CertificateFiles{})
 			basicCreds := basicAuth.ApiServiceLogin.(*client.TapApiServiceApiBasicAuthConnector)
 
 			So(basicCreds.Address, ShouldEqual, "http://myaddress.com")
 		})
 		Convey("when there is ftp:// ", func() {
-			basicAuth := newBasicAuthService("ftp://myaddress.com", "user", "password", false)
+			basicAuth := newBasicAuthService("ftp://myaddress.com", "user", "password", false, api.CertificateFiles{})
 			basicCreds := basicAuth.ApiServiceLogin.(*client.TapApiServiceApiBasicAuthConnector)
 
 			So(basicCreds.Address, ShouldEqual, "ftp://myaddress.com")
@@ -137,3 +285,24 @@ func TestValidateAndSplitEnvFlags(t *testing.T) {
 		So(err, ShouldNotBeNil)
 	})
 }
+
+func TestValidateGrepScope(t *testing.T) {
+	Convey("Test validating scope of logs grep", t, func() {
+		Convey("Should allow searching all applications and all services together", func() {
+			So(validateGrepScope(actions.LogGrepOptions{AllApplications: true, AllServices: true}), ShouldBeNil)
+			So(validateGrepScope(actions.LogGrepOptions{AllServices: true}), ShouldBeNil)
+			So(validateGrepScope(actions.LogGrepOptions{InstanceNames: []string{"my-app"}}), ShouldBeNil)
+		})
+
+		Convey("Should reject names used together with all applications or services", func() {
+			err := validateGrepScope(actions.LogGrepOptions{InstanceNames: []string{"my-app"}, AllApplications: true})
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "--name cannot be used together")
+		})
+
+		Convey("Should require some instances to search", func() {
+			So(validateGrepScope(actions.LogGrepOptions{}), ShouldNotBeNil)
+		})
+	})
+}
@@ -16,7 +16,11 @@
 
 package commands
 
-import "github.com/urfave/cli"
+import (
+	"github.com/urfave/cli"
+
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
+)
 
 func getInstanceLogsCommand() cli.Command {
 	return cli.Command{
@@ -35,7 +39,7 @@ func getInstanceLogsCommand() cli.Command {
 				return err
 			}
 
-			return newOAuth2Service().GetInstanceLogs(c.Args().First())
+			return newOAuth2Service().GetInstanceLogs(c.Args().First(), actions.LogOptions{})
 		},
 	}
 }
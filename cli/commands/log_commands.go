@@ -0,0 +1,140 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"github.com/urfave/cli"
+
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
+)
+
+// logFlags returns flags narrowing down and following instance logs
+func logFlags(options *actions.LogOptions) []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:        "follow",
+			Usage:       "keep polling logs and print new lines as they arrive",
+			Destination: &options.Follow,
+		},
+		cli.IntFlag{
+			Name:        "tail",
+			Usage:       "print only last `number` of lines of every container",
+			Destination: &options.Tail,
+		},
+		cli.DurationFlag{
+			Name:        "since",
+			Usage:       "print only lines logged within given `time`, e.g. 30s or 1h",
+			Destination: &options.Since,
+		},
+		cli.StringFlag{
+			Name:        "container",
+			Usage:       "print logs of `container` only",
+			Destination: &options.Container,
+		},
+	}
+}
+
+func logsCommand() TapCommand {
+	var instanceNames cli.StringSlice
+	var instanceNamesFlag = cli.StringSliceFlag{
+		Name:  "name",
+		Usage: "`name` of application or service instance, this flag can be used multiple times",
+		Value: &instanceNames,
+	}
+
+	var dir string
+	var dirFlag = cli.StringFlag{
+		Name:        "dir",
+		Usage:       "`directory` to write logs to",
+		Destination: &dir,
+	}
+
+	var archivePath string
+	var archiveFlag = cli.StringFlag{
+		Name:        "archive",
+		Usage:       "additionally bundle exported files into tar.gz archive at `path`",
+		Destination: &archivePath,
+	}
+
+	var exportLogsCommand = TapCommand{
+		Name:          "export",
+		Usage:         "write logs of every container of instances to separate files, together with instances details",
+		RequiredFlags: []cli.Flag{instanceNamesFlag, dirFlag},
+		OptionalFlags: []cli.Flag{archiveFlag},
+		MainAction: func(c *cli.Context) error {
+			return newOAuth2Service().ExportInstanceLogs(instanceNames, dir, archivePath)
+		},
+	}
+
+	var grepOptions actions.LogGrepOptions
+	var patternFlag = cli.StringFlag{
+		Name:        "pattern",
+		Usage:       "regular `expression` which printed lines have to match",
+		Destination: &grepOptions.Pattern,
+	}
+
+	var severityFlag = cli.StringFlag{
+		Name:        "severity",
+		Usage:       "print only lines of `level` or more severe one [ERROR,WARN,INFO,DEBUG]",
+		Destination: &grepOptions.Severity,
+	}
+
+	var allApplicationsFlag = cli.BoolFlag{
+		Name:        "all-applications",
+		Usage:       "search logs of all applications",
+		Destination: &grepOptions.AllApplications,
+	}
+
+	var allServicesFlag = cli.BoolFlag{
+		Name:        "all-services",
+		Usage:       "search logs of all service instances",
+		Destination: &grepOptions.AllServices,
+	}
+
+	var grepLogsCommand = TapCommand{
+		Name:          "grep",
+		Usage:         "print lines of instances logs matching pattern, prefixed with instance and container name",
+		RequiredFlags: []cli.Flag{patternFlag},
+		OptionalFlags: []cli.Flag{instanceNamesFlag, allApplicationsFlag, allServicesFlag, severityFlag},
+		MainAction: func(c *cli.Context) error {
+			grepOptions.InstanceNames = instanceNames
+			if err := validateGrepScope(grepOptions); err != nil {
+				return err
+			}
+			return newOAuth2Service().GrepInstanceLogs(grepOptions)
+		},
+	}
+
+	return TapCommand{
+		Name:        "logs",
+		Usage:       "logs of many instances",
+		Subcommands: []TapCommand{exportLogsCommand, grepLogsCommand},
+	}
+}
+
+// validateGrepScope checks that logs are searched either in named instances, or in all applications
+// and/or all service instances
+func validateGrepScope(options actions.LogGrepOptions) error {
+	all := options.AllApplications || options.AllServices
+	if len(options.InstanceNames) > 0 && all {
+		return cli.NewExitError("--name cannot be used together with --all-applications or --all-services", 1)
+	}
+	if len(options.InstanceNames) == 0 && !all {
+		return cli.NewExitError("one of --name, --all-applications or --all-services is required", 1)
+	}
+	return nil
+}
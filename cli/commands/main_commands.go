@@ -17,6 +17,7 @@
 package commands
 
 import (
+	"path/filepath"
 	"strings"
 
 	"fmt"
@@ -66,16 +67,112 @@ func loginCommand() TapCommand {
 		Destination: &skipSSLValidation,
 	}
 
+	var encryptToken bool
+	var encryptTokenFlag = cli.BoolFlag{
+		Name:        "encrypt-token",
+		Usage:       "store token encrypted with passphrase, which is prompted for or taken from " + passphraseEnvVar,
+		Destination: &encryptToken,
+	}
+
+	var certificateFiles api.CertificateFiles
+	var caCertFlag = cli.StringFlag{
+		Name:        "ca-cert",
+		Usage:       "`path` to PEM file with CA certificates trusted instead of system ones",
+		Destination: &certificateFiles.CACert,
+	}
+	var clientCertFlag = cli.StringFlag{
+		Name:        "client-cert",
+		Usage:       "`path` to PEM file with client certificate presented to API service",
+		Destination: &certificateFiles.ClientCert,
+	}
+	var clientKeyFlag = cli.StringFlag{
+		Name:        "client-key",
+		Usage:       "`path` to PEM file with key of client certificate",
+		Destination: &certificateFiles.ClientKey,
+	}
+
 	return TapCommand{
 		Name:          "login",
 		Usage:         "login to TAP. If you don't provide password you'll be prompted for it.",
-		OptionalFlags: []cli.Flag{passwordFlag, skipSSLValidationFlag},
+		OptionalFlags: []cli.Flag{passwordFlag, skipSSLValidationFlag, caCertFlag, clientCertFlag, clientKeyFlag, encryptTokenFlag},
 		RequiredFlags: []cli.Flag{apiFlag, usernameFlag},
 		MainAction: func(c *cli.Context) error {
+			files, err := absoluteCertificateFiles(certificateFiles)
+			if err != nil {
+				return err
+			}
 			if password == "" {
 				password = promptForSensitive("Password")
 			}
-			return newBasicAuthService(apiUrl, username, password, skipSSLValidation).Login(skipSSLValidation)
+			loginService := newBasicAuthService(apiUrl, username, password, skipSSLValidation, files)
+			loginService.EncryptTokens = encryptToken
+			return loginService.Login(skipSSLValidation, files)
+		},
+	}
+}
+
+// absoluteCertificateFiles makes paths independent of directory, from which CLI will be called later
+func absoluteCertificateFiles(files api.CertificateFiles) (api.CertificateFiles, error) {
+	var err error
+	for _, path := range []*string{&files.CACert, &files.ClientCert, &files.ClientKey} {
+		if *path == "" {
+			continue
+		}
+		if *path, err = filepath.Abs(*path); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// runWithRelogin runs action and, when it fails because session expired, offers to login again
+// and retries action once. Action is retried only when it did not change anything before failure,
+// as its steps which succeeded would be repeated. In other cases and in non-interactive mode it fails
+// with sessionExpiredExitCode instead.
+func runWithRelogin(c *cli.Context, action func(c *cli.Context) error) error {
+	responses.Reset()
+	err := action(c)
+	if err == nil || !responses.Unauthorized() {
+		return err
+	}
+
+	if responses.MadeChanges() {
+		return cli.NewExitError(actions.ErrSessionExpired.Error()+
+			", command was interrupted after making changes, check their state before running it again", sessionExpiredExitCode)
+	}
+	sessionExpiredErr := cli.NewExitError(actions.ErrSessionExpired.Error(), sessionExpiredExitCode)
+	creds, credsErr := newCredentialsService().GetCredentials()
+	if tokenOverride != "" || credsErr != nil || !isInteractive() {
+		return sessionExpiredErr
+	}
+	if !confirmationPrompt(fmt.Sprintf("Session expired. Do you want to login to %s as %s again?", creds.Address, creds.Username)) {
+		return sessionExpiredErr
+	}
+
+	password := promptForSensitive("Password")
+	loginService := newBasicAuthService(creds.Address, creds.Username, password, creds.SkipSSLValidation, creds.CertificateFiles)
+	loginService.EncryptTokens = creds.EncryptedToken != ""
+	if err = loginService.Login(creds.SkipSSLValidation, creds.CertificateFiles); err != nil {
+		return err
+	}
+	responses.Reset()
+	return action(c)
+}
+
+func logoutCommand() TapCommand {
+	var all bool
+	var allFlag = cli.BoolFlag{
+		Name:        "all",
+		Usage:       "logout from all stored targets",
+		Destination: &all,
+	}
+
+	return TapCommand{
+		Name:          "logout",
+		Usage:         "remove token of current target from stored credentials",
+		OptionalFlags: []cli.Flag{allFlag},
+		MainAction: func(c *cli.Context) error {
+			return newCredentialsService().Logout(all)
 		},
 	}
 }
@@ -95,16 +192,25 @@ func promptForSensitive(name string) string {
 	return password
 }
 
-func newBasicAuthService(address string, username string, password string, skipSSLValidation bool) *actions.ActionsConfig {
+func newBasicAuthService(address string, username string, password string, skipSSLValidation bool,
+	certificateFiles api.CertificateFiles) *actions.ActionsConfig {
+
+	address = normalizeApiAddress(address)
+	httpClient, err := api.NewHttpClient(skipSSLValidation, certificateFiles)
+	if err != nil {
+		panic(err)
+	}
+	apiConnector := &client.TapApiServiceApiBasicAuthConnector{Address: address, Username: username, Password: password, Client: httpClient}
+	return &actions.ActionsConfig{Config: api.Config{ApiService: nil, ApiServiceLogin: apiConnector, Target: targetName,
+		Passphrase: passphrase}}
+}
+
+func normalizeApiAddress(address string) string {
 	address = trimEndingSlash(address)
 	if !isProcotolSet(address) {
 		address = "https://" + address
 	}
-	apiConnector, err := client.NewTapApiServiceLoginApiWithSSLValidationAndBasicAuth(address, username, password, skipSSLValidation)
-	if err != nil {
-		panic(err)
-	}
-	return &actions.ActionsConfig{Config: api.Config{ApiService: nil, ApiServiceLogin: apiConnector}}
+	return address
 }
 
 func trimEndingSlash(str string) string {
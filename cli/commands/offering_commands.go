@@ -29,7 +29,7 @@ func offeringCommand() TapCommand {
 	var manifestPath string
 	var manifestFlag = cli.StringFlag{
 		Name:        "manifest",
-		Usage:       "`path to json` with service definition",
+		Usage:       "`path to json or yaml file` with service definition",
 		Value:       "manifest.json",
 		Destination: &manifestPath,
 	}
@@ -41,6 +41,13 @@ func offeringCommand() TapCommand {
 		Destination: &confirmed,
 	}
 
+	cascade := false
+	var cascadeFlag = cli.BoolFlag{
+		Name:        "cascade",
+		Usage:       "delete service instances created from offering, unbinding them first, before deletion",
+		Destination: &cascade,
+	}
+
 	var infoOfferingCommand = TapCommand{
 		Name:          "info",
 		Usage:         "show information about specific offering",
@@ -69,15 +76,16 @@ func offeringCommand() TapCommand {
 
 	var deleteOfferingCommand = TapCommand{
 		Name:          "delete",
-		Usage:         "delete offering",
+		Usage:         "delete offering, refusing when service instances are created from it unless --cascade is given",
 		RequiredFlags: []cli.Flag{nameFlag},
-		OptionalFlags: []cli.Flag{confirmationFlag},
+		OptionalFlags: []cli.Flag{confirmationFlag, cascadeFlag},
 		MainAction: func(c *cli.Context) error {
-			if !confirmed {
-				err := removalConfirmationPrompt("offering " + name)
-				cli.HandleExitCoder(err)
+			a := newOAuth2Service()
+			plan, err := a.PlanOfferingDeletion(name)
+			if err != nil {
+				return err
 			}
-			return newOAuth2Service().DeleteOffering(name)
+			return deleteWithDependents(a, plan, confirmed, cascade)
 		},
 	}
 
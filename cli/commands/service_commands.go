@@ -20,6 +20,7 @@ import (
 	"github.com/urfave/cli"
 
 	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
 )
 
 func serviceCommand() TapCommand {
@@ -59,6 +60,16 @@ func serviceCommand() TapCommand {
 		Destination: &confirmed,
 	}
 
+	cascade := false
+	var cascadeFlag = cli.BoolFlag{
+		Name:        "cascade",
+		Usage:       "unbind service instance from instances using it before deletion",
+		Destination: &cascade,
+	}
+
+	wait := &waitOptions{}
+	var logOptions actions.LogOptions
+
 	var listServiceCommand = TapCommand{
 		Name:  "list",
 		Usage: "list services",
@@ -80,27 +91,31 @@ func serviceCommand() TapCommand {
 		Name:          "create",
 		Usage:         "create new service instance",
 		RequiredFlags: []cli.Flag{serviceNameFlag, offeringNameFlag, planNameFlag},
-		OptionalFlags: []cli.Flag{envFlag},
+		OptionalFlags: append([]cli.Flag{envFlag}, wait.flags()...),
 		MainAction: func(c *cli.Context) error {
 			splitEnvs, err := validateAndSplitEnvFlags(envs)
 			if err != nil {
 				return err
 			}
-			return newOAuth2Service().CreateServiceInstance(offeringName, planName, serviceName, splitEnvs)
+			a := newOAuth2Service()
+			createErr := a.CreateServiceInstance(offeringName, planName, serviceName, splitEnvs)
+			return wait.waitForNew(a, createErr, catalogModels.InstanceTypeService, serviceName,
+				actions.StateCondition(catalogModels.InstanceStateRunning))
 		},
 	}
 
 	var deleteServiceCommand = TapCommand{
 		Name:          "delete",
-		Usage:         "delete service instance",
+		Usage:         "delete service instance, refusing when other instances use it unless --cascade is given",
 		RequiredFlags: []cli.Flag{serviceNameFlag},
-		OptionalFlags: []cli.Flag{confirmationFlag},
+		OptionalFlags: []cli.Flag{confirmationFlag, cascadeFlag},
 		MainAction: func(c *cli.Context) error {
-			if !confirmed {
-				err := removalConfirmationPrompt("service instance " + serviceName)
-				cli.HandleExitCoder(err)
+			a := newOAuth2Service()
+			plan, err := a.PlanInstanceDeletion(catalogModels.InstanceTypeService, serviceName)
+			if err != nil {
+				return err
 			}
-			return newOAuth2Service().DeleteService(serviceName)
+			return deleteWithDependents(a, plan, confirmed, cascade)
 		},
 	}
 
@@ -108,8 +123,12 @@ func serviceCommand() TapCommand {
 		Name:          "start",
 		Usage:         "start service instance",
 		RequiredFlags: []cli.Flag{serviceNameFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().StartService(serviceName)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeService, serviceName, actions.StateCondition(catalogModels.InstanceStateRunning), func() error {
+				return a.StartService(serviceName)
+			})
 		},
 	}
 
@@ -117,8 +136,12 @@ func serviceCommand() TapCommand {
 		Name:          "stop",
 		Usage:         "stop service instance",
 		RequiredFlags: []cli.Flag{serviceNameFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().StopService(serviceName)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeService, serviceName, actions.StateCondition(catalogModels.InstanceStateStopped), func() error {
+				return a.StopService(serviceName)
+			})
 		},
 	}
 
@@ -126,8 +149,12 @@ func serviceCommand() TapCommand {
 		Name:          "restart",
 		Usage:         "restart service instance",
 		RequiredFlags: []cli.Flag{serviceNameFlag},
+		OptionalFlags: wait.flags(),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().RestartService(serviceName)
+			a := newOAuth2Service()
+			return wait.waitFor(a, catalogModels.InstanceTypeService, serviceName, actions.StateCondition(catalogModels.InstanceStateRunning), func() error {
+				return a.RestartService(serviceName)
+			})
 		},
 	}
 
@@ -135,8 +162,9 @@ func serviceCommand() TapCommand {
 		Name:          "show",
 		Usage:         "show service instances's logs",
 		RequiredFlags: []cli.Flag{serviceNameFlag},
+		OptionalFlags: logFlags(&logOptions),
 		MainAction: func(c *cli.Context) error {
-			return newOAuth2Service().GetInstanceLogs(serviceName)
+			return newOAuth2Service().GetInstanceLogs(serviceName, logOptions)
 		},
 	}
 
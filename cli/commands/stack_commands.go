@@ -0,0 +1,137 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"github.com/urfave/cli"
+
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
+)
+
+func applyCommand() TapCommand {
+	var stackPath string
+	var stackFlag = cli.StringFlag{
+		Name:        "file,f",
+		Usage:       "`path` to json or yaml file with stack of services, applications and bindings",
+		Destination: &stackPath,
+	}
+
+	prune := false
+	var pruneFlag = cli.BoolFlag{
+		Name:        "prune",
+		Usage:       "delete instances and bindings which are not declared in stack",
+		Destination: &prune,
+	}
+
+	dryRun := false
+	var dryRunFlag = cli.BoolFlag{
+		Name:        "dry-run",
+		Usage:       "only print changes which would be made",
+		Destination: &dryRun,
+	}
+
+	confirmed := false
+	var confirmationFlag = cli.BoolFlag{
+		Name:        "yes",
+		Usage:       "apply changes without confirmation",
+		Destination: &confirmed,
+	}
+
+	return TapCommand{
+		Name:          "apply",
+		Usage:         "create, update or delete instances and bindings to match stack file, printing plan of changes first",
+		RequiredFlags: []cli.Flag{stackFlag},
+		OptionalFlags: []cli.Flag{pruneFlag, dryRunFlag, confirmationFlag},
+		MainAction: func(c *cli.Context) error {
+			a := newOAuth2Service()
+			plan, err := a.PlanStack(stackPath, prune)
+			if err != nil {
+				return err
+			}
+			plan.Print()
+			if dryRun || plan.IsEmpty() {
+				return nil
+			}
+			if !confirmed && !confirmationPrompt("Apply these changes?") {
+				return cli.NewExitError("Canceled", -1)
+			}
+			return a.ApplyStackPlan(plan)
+		},
+	}
+}
+
+func exportCommand() TapCommand {
+	return TapCommand{
+		Name:  "export",
+		Usage: "print stack file describing service instances, applications and bindings existing on target",
+		MainAction: func(c *cli.Context) error {
+			return newOAuth2Service().ExportStack()
+		},
+	}
+}
+
+func diffCommand() TapCommand {
+	var fromTarget string
+	var fromTargetFlag = cli.StringFlag{
+		Name:        "from-target",
+		Usage:       "`name` of stored target to compare with, current target is used when not given",
+		Destination: &fromTarget,
+	}
+
+	var toTarget string
+	var toTargetFlag = cli.StringFlag{
+		Name:        "to-target",
+		Usage:       "`name` of stored target compared with base one",
+		Destination: &toTarget,
+	}
+
+	var stackPath string
+	var stackFlag = cli.StringFlag{
+		Name:        "file,f",
+		Usage:       "`path` to json or yaml stack file compared with base target",
+		Destination: &stackPath,
+	}
+
+	return TapCommand{
+		Name:             "diff",
+		Usage:            "compare instances, plans, replicas, envs and bindings of two targets or of target and stack file",
+		AlternativeFlags: []cli.Flag{toTargetFlag, stackFlag},
+		OptionalFlags:    []cli.Flag{fromTargetFlag},
+		MainAction: func(c *cli.Context) error {
+			from, fromName := newOAuth2Service(), "current target"
+			if fromTarget != "" {
+				from, fromName = newOAuth2ServiceForTarget(fromTarget), "target "+fromTarget
+			}
+
+			var diff *actions.StackDiff
+			var err error
+			if stackPath != "" {
+				diff, err = from.DiffStackFile(stackPath, fromName)
+			} else {
+				diff, err = from.DiffTargets(newOAuth2ServiceForTarget(toTarget), fromName, "target "+toTarget)
+			}
+			if err != nil {
+				return err
+			}
+			diff.Print()
+			if diff.HasDrift() {
+				return cli.NewExitError("Drift detected", driftDetectedExitCode)
+			}
+			return nil
+		},
+	}
+}
@@ -52,7 +52,7 @@ func (tc TapCommand) ToCliCommand() cli.Command {
 		Aliases:     tc.Aliases,
 		Subcommands: toCommands(tc.Subcommands, tc.DefaultSubcommand),
 		ArgsUsage:   getArgsUsage(requiredFlags, alternativeFlags, optionalFlags),
-		Flags:       sumFlags(requiredFlags, optionalFlags, alternativeFlags, GetCommonFlags()),
+		Flags:       withCommonFlags(sumFlags(requiredFlags, optionalFlags, alternativeFlags)),
 		Action: func(c *cli.Context) error {
 			if err := handleCommonFlags(c); err != nil {
 				return err
@@ -79,11 +79,11 @@ func (tc TapCommand) ToCliCommand() cli.Command {
 					cli.ShowCommandHelp(c, tc.DefaultSubcommand.Name)
 					return nil
 				}
-				return tc.DefaultSubcommand.MainAction(c)
+				return withExitCode(runWithRelogin(c, tc.DefaultSubcommand.MainAction))
 			} else if tc.MainAction == nil {
 				return nil
 			}
-			return tc.MainAction(c)
+			return withExitCode(runWithRelogin(c, tc.MainAction))
 		},
 	}
 }
@@ -145,6 +145,8 @@ func extractFlagUse(flag cli.Flag) string {
 	//this returns string in a form: "--api API     TAP API you would like to use" (used in OPTIONS section)
 	//I parse this string to comply to placeholders names used in this section
 	splitted := strings.Split(stringifiedFlag, "\t")
+	// aliases are skipped: "--file path, -f path" is turned into "--file path"
+	splitted[0] = strings.Split(splitted[0], ", ")[0]
 	if _, ok := flag.(cli.BoolFlag); ok {
 		return splitted[0]
 	}
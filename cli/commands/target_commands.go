@@ -0,0 +1,82 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import "github.com/urfave/cli"
+
+func targetCommand() TapCommand {
+	var name string
+	var nameFlag = cli.StringFlag{
+		Name:        "name",
+		Usage:       "`target name`",
+		Destination: &name,
+	}
+
+	confirmed := false
+	var confirmationFlag = cli.BoolFlag{
+		Name:        "yes",
+		Usage:       "use with caution when want to suppress removal confirmation",
+		Destination: &confirmed,
+	}
+
+	var listTargetsCommand = TapCommand{
+		Name:  "list",
+		Usage: "list stored targets",
+		MainAction: func(c *cli.Context) error {
+			return newCredentialsService().ListTargets()
+		},
+	}
+
+	var useTargetCommand = TapCommand{
+		Name:          "use",
+		Usage:         "switch current target, name can be also given as argument: target use <name>",
+		OptionalFlags: []cli.Flag{nameFlag},
+		MainAction: func(c *cli.Context) error {
+			if err := nameFromFlagOrArg(c, &name); err != nil {
+				return err
+			}
+			return newCredentialsService().UseTarget(name)
+		},
+	}
+
+	var deleteTargetCommand = TapCommand{
+		Name:          "delete",
+		Usage:         "delete stored target with its credentials, name can be also given as argument: target delete <name>",
+		OptionalFlags: []cli.Flag{nameFlag, confirmationFlag},
+		MainAction: func(c *cli.Context) error {
+			if err := nameFromFlagOrArg(c, &name); err != nil {
+				return err
+			}
+			if !confirmed {
+				err := removalConfirmationPrompt("target " + name)
+				cli.HandleExitCoder(err)
+			}
+			return newCredentialsService().DeleteTarget(name)
+		},
+	}
+
+	return TapCommand{
+		Name:  "target",
+		Usage: "stored targets (TAP platforms) context commands",
+		Subcommands: []TapCommand{
+			listTargetsCommand,
+			useTargetCommand,
+			deleteTargetCommand,
+		},
+		DefaultSubcommand: &listTargetsCommand,
+	}
+}
@@ -0,0 +1,126 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"time"
+
+	"github.com/urfave/cli"
+
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+	"github.com/trustedanalytics-ng/tap-cli/cli/actions"
+	"github.com/trustedanalytics-ng/tap-cli/cli/converter"
+)
+
+// waitOptions are set by flags making lifecycle commands wait until instance reaches expected state
+type waitOptions struct {
+	wait    bool
+	timeout time.Duration
+}
+
+func (w *waitOptions) flags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:        "wait",
+			Usage:       "wait until instance reaches expected state",
+			Destination: &w.wait,
+		},
+		cli.DurationFlag{
+			Name:        "wait-timeout",
+			Usage:       "maximum `time` of waiting, e.g. 90s or 10m",
+			Value:       actions.DefaultWaitTimeout,
+			Destination: &w.timeout,
+		},
+	}
+}
+
+// waitFor runs action and, when --wait flag is set, waits until instance meets condition. Status of instance
+// is recorded before the action, so that state left from before it is not taken for its result.
+func (w *waitOptions) waitFor(a *actions.ActionsConfig, instanceType catalogModels.InstanceType,
+	instanceName string, condition actions.WaitCondition, action func() error) error {
+
+	if !w.wait {
+		return action()
+	}
+	initial, err := a.GetInstanceStatus(instanceType, instanceName)
+	if err != nil {
+		return err
+	}
+	if err := action(); err != nil {
+		return err
+	}
+	return a.WaitForInstanceChange(instanceType, instanceName, initial, condition, w.timeout)
+}
+
+// waitForNew waits for instance created by successful action when --wait flag is set
+func (w *waitOptions) waitForNew(a *actions.ActionsConfig, err error, instanceType catalogModels.InstanceType,
+	instanceName string, condition actions.WaitCondition) error {
+
+	if err != nil || !w.wait {
+		return err
+	}
+	return a.WaitForInstanceChange(instanceType, instanceName, actions.InstanceStatus{}, condition, w.timeout)
+}
+
+// withExitCode gives distinct exit codes to errors of waiting for instance
+func withExitCode(err error) error {
+	switch err.(type) {
+	case *actions.WaitTimeoutError:
+		return cli.NewExitError(err.Error(), waitTimeoutExitCode)
+	case *actions.InstanceFailureError:
+		return cli.NewExitError(err.Error(), instanceFailureExitCode)
+	}
+	return err
+}
+
+func waitCommand() TapCommand {
+	var instanceName string
+	var instanceNameFlag = cli.StringFlag{
+		Name:        "name",
+		Usage:       "`name` of application or service instance",
+		Destination: &instanceName,
+	}
+
+	var conditionExpression string
+	var conditionFlag = cli.StringFlag{
+		Name:        "for",
+		Usage:       "`condition`: state=STATE, image-state=STATE, replicas=NUMBER or deleted",
+		Destination: &conditionExpression,
+	}
+
+	var timeout time.Duration
+	var timeoutFlag = cli.DurationFlag{
+		Name:        "timeout",
+		Usage:       "maximum `time` of waiting, e.g. 90s or 10m",
+		Value:       actions.DefaultWaitTimeout,
+		Destination: &timeout,
+	}
+
+	return TapCommand{
+		Name:          "wait",
+		Usage:         "wait until application or service instance meets condition",
+		RequiredFlags: []cli.Flag{instanceNameFlag, conditionFlag},
+		OptionalFlags: []cli.Flag{timeoutFlag},
+		MainAction: func(c *cli.Context) error {
+			condition, err := actions.ParseWaitCondition(conditionExpression)
+			if err != nil {
+				return cli.NewExitError(err.Error(), 1)
+			}
+			return newOAuth2Service().WaitForInstance(converter.InstanceTypeBoth, instanceName, condition, timeout)
+		},
+	}
+}
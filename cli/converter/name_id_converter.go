@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/trustedanalytics-ng/tap-api-service/models"
 	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
 	"github.com/trustedanalytics-ng/tap-cli/api"
 )
@@ -47,6 +48,25 @@ func FetchServiceAndPlanID(apiConfig api.Config, serviceName, planName string) (
 	return "", "", errors.New("cannot find service: '" + serviceName + "'")
 }
 
+// FindOfferingAndPlanNames looks up names of offering and its plan by their ids in already fetched catalog
+func FindOfferingAndPlanNames(catalog []models.Offering, offeringID, planID string) (string, string, error) {
+
+	for _, service := range catalog {
+
+		if service.Id == offeringID {
+			for _, plan := range service.OfferingPlans {
+
+				if plan.Id == planID {
+					return service.Name, plan.Name, nil
+				}
+			}
+			return "", "", errors.New("cannot find plan with id: '" + planID + "' for service: '" + service.Name + "'")
+		}
+	}
+
+	return "", "", errors.New("cannot find service with id: '" + offeringID + "'")
+}
+
 const (
 	InstanceTypeBoth catalogModels.InstanceType = "BOTH"
 )
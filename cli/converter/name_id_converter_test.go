@@ -102,6 +102,32 @@ func TestConvertFunction(t *testing.T) {
 	})
 }
 
+func TestFindOfferingAndPlanNames(t *testing.T) {
+	Convey("Test FindOfferingAndPlanNames", t, func() {
+		fakeServices := getFakeServices()
+
+		Convey("Should fail when given plan doesn't exist", func() {
+			_, _, err := FindOfferingAndPlanNames(fakeServices, "offering_id_1", "plan_id_2")
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "cannot find plan with id: 'plan_id_2' for service: 'name_1'")
+		})
+		Convey("Should fail when given service doesn't exist", func() {
+			_, _, err := FindOfferingAndPlanNames(fakeServices, "wrong_id", "plan_id_1")
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "cannot find service with id: 'wrong_id'")
+		})
+		Convey("Should pass when service and plan names returned succesfully", func() {
+			serviceName, planName, err := FindOfferingAndPlanNames(fakeServices, "offering_id_2", "plan_id_2")
+
+			So(err, ShouldBeNil)
+			So(serviceName, ShouldEqual, "name_2")
+			So(planName, ShouldEqual, "plan_2")
+		})
+	})
+}
+
 func TestGetServiceID(t *testing.T) {
 	Convey("Test getServiceID", t, func() {
 		apiConfig, mockCtrl := test.SetApiAndLoginServiceMocks(t)
@@ -0,0 +1,107 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+var imageTypes = []string{
+	string(catalogModels.ImageTypeJava),
+	string(catalogModels.ImageTypeGo),
+	string(catalogModels.ImageTypeNodeJs),
+	string(catalogModels.ImageTypePython27),
+	string(catalogModels.ImageTypePython34),
+}
+
+// ApplicationManifestFileNames are names of manifest files looked up in application directory
+var ApplicationManifestFileNames = []string{"manifest.json", "manifest.yaml", "manifest.yml"}
+
+// ApplicationManifest is description of pushed application read from file
+type ApplicationManifest struct {
+	File     string
+	manifest models.Manifest
+	// decodingProblems are mismatched types and unknown keys, reported together with validation ones
+	decodingProblems problems
+}
+
+// FindApplicationManifest returns first of ApplicationManifestFileNames existing in current directory
+func FindApplicationManifest() (string, bool) {
+	return FindApplicationManifestIn(".")
+}
+
+// FindApplicationManifestIn returns path of first of ApplicationManifestFileNames existing in folder
+func FindApplicationManifestIn(folder string) (string, bool) {
+	for _, fileName := range ApplicationManifestFileNames {
+		path := filepath.Join(folder, fileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// ReadApplicationManifest reads manifest from JSON or YAML file. Returned ValidationError points
+// where syntax of the file is broken.
+func ReadApplicationManifest(path string) (*ApplicationManifest, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &ApplicationManifest{File: path}
+	if err = doc.decodeStrict(&m.manifest, &m.decodingProblems); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// HasBindings tells if manifest binds application to any service instance
+func (m *ApplicationManifest) HasBindings() bool {
+	return len(m.manifest.Bindings) > 0
+}
+
+// Validate reports all problems found in manifest at once. Bindings are checked against
+// names of existing service instances, unless serviceInstances is nil.
+func (m *ApplicationManifest) Validate(serviceInstances []string) (models.Manifest, error) {
+	found := append(problems{}, m.decodingProblems...)
+
+	checkRequired(m.manifest.Name, "name", &found)
+	imageType := string(m.manifest.ImageType)
+	if checkRequired(imageType, "type", &found) && !contains(imageTypes, imageType) {
+		found.add("type %q is not supported, expected one of: %s", imageType, joinQuoted(imageTypes))
+	}
+	if m.manifest.Instances <= 0 {
+		found.add("instances should be a positive integer, got %d", m.manifest.Instances)
+	}
+	for i, binding := range m.manifest.Bindings {
+		if !checkRequired(binding, fmt.Sprintf("bindings[%d]", i), &found) {
+			continue
+		}
+		if serviceInstances != nil && !contains(serviceInstances, binding) {
+			found.add("service instance %q used in bindings does not exist", binding)
+		}
+	}
+	for i, entry := range m.manifest.Metadata {
+		checkRequired(entry.Id, fmt.Sprintf("metadata[%d].key", i), &found)
+	}
+	return m.manifest, found.toError(m.File)
+}
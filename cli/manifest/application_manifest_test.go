@@ -0,0 +1,232 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestManifest(t *testing.T, content string) (string, func()) {
+	return writeTestFile(t, "manifest.json", content)
+}
+
+func writeTestFile(t *testing.T, fileName, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, fileName)
+	if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func validateTestManifest(t *testing.T, content string, serviceInstances []string) error {
+	path, cleanup := writeTestManifest(t, content)
+	defer cleanup()
+
+	appManifest, err := ReadApplicationManifest(path)
+	if err != nil {
+		return err
+	}
+	_, err = appManifest.Validate(serviceInstances)
+	return err
+}
+
+func problemsOf(t *testing.T, err error) []string {
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	messages := []string{}
+	for _, problem := range validationErr.Problems {
+		messages = append(messages, problem.Error())
+	}
+	return messages
+}
+
+func expectProblems(t *testing.T, err error, expected ...string) {
+	messages := problemsOf(t, err)
+	if len(messages) != len(expected) {
+		t.Fatalf("reported problems %q, expected %q", messages, expected)
+	}
+	for i := range expected {
+		if messages[i] != expected[i] {
+			t.Errorf("reported problem %q, expected %q", messages[i], expected[i])
+		}
+	}
+}
+
+func TestThatValidate_acceptsCorrectManifest(t *testing.T) {
+	path, cleanup := writeTestManifest(t, `{
+    "type": "PYTHON2.7",
+    "name": "my-app",
+    "instances": 2,
+    "bindings": ["db"],
+    "metadata": [{"key": "ENV", "value": "prod"}]
+}`)
+	defer cleanup()
+
+	appManifest, err := ReadApplicationManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !appManifest.HasBindings() {
+		t.Error("manifest should have bindings")
+	}
+	manifest, err := appManifest.Validate([]string{"db", "queue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "my-app" || manifest.Instances != 2 || manifest.Bindings[0] != "db" || manifest.Metadata[0].Value != "prod" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestThatValidate_reportsAllProblems(t *testing.T) {
+	err := validateTestManifest(t, `{
+  "name": "",
+  "type": "PYTHON",
+  "instances": 0,
+  "bindings": ["db", "missing"],
+  "metadata": [{"key": "", "value": "prod"}],
+  "instance": 1
+}`, []string{"db"})
+
+	expectProblems(t, err,
+		`unknown field "instance"`,
+		`name is required`,
+		`type "PYTHON" is not supported, expected one of: "JAVA", "GO", "NODEJS", "PYTHON2.7", "PYTHON3.4"`,
+		`instances should be a positive integer, got 0`,
+		`service instance "missing" used in bindings does not exist`,
+		`metadata[0].key is required`,
+	)
+}
+
+func TestThatValidate_reportsMissingKeysAndWrongTypesWithPosition(t *testing.T) {
+	err := validateTestManifest(t, `{"name": "app",
+  "bindings": "db"}`, nil)
+
+	expectProblems(t, err,
+		`2: cannot unmarshal string into bindings of type array`,
+		`type is required`,
+		`instances should be a positive integer, got 0`,
+	)
+}
+
+func TestThatValidate_skipsBindingsCheckWithoutServiceInstances(t *testing.T) {
+	err := validateTestManifest(t, `{"name": "app", "type": "GO", "instances": 1, "bindings": ["missing"]}`, nil)
+
+	if err != nil {
+		t.Errorf("bindings should not be checked: %v", err)
+	}
+}
+
+func TestThatReadApplicationManifest_reportsSyntaxErrorPosition(t *testing.T) {
+	testCases := []struct {
+		content  string
+		expected string
+	}{
+		{"{\n  \"name\": \"app\"\n  \"type\": \"GO\"\n}", `3:3: invalid character '"' after object key:value pair`},
+		{`{"name": "app",}`, `1:16: invalid character '}' looking for beginning of object key string`},
+		{`{"name": tru}`, `1:13: invalid character '}' in literal true (expecting 'e')`},
+		{`{"name": "app"`, `1:14: unexpected end of document`},
+		{`{} {}`, `1:4: unexpected data after end of document`},
+	}
+
+	for _, tc := range testCases {
+		err := validateTestManifest(t, tc.content, nil)
+		expectProblems(t, err, tc.expected)
+	}
+}
+
+func TestThatValidate_reportsProblemsInYAMLManifest(t *testing.T) {
+	path, cleanup := writeTestFile(t, "manifest.yml", `# application
+name: my-app
+type: RUBY
+instances: 0
+bindings:
+  - db
+  - missing
+`)
+	defer cleanup()
+
+	appManifest, err := ReadApplicationManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = appManifest.Validate([]string{"db"})
+
+	expectProblems(t, err,
+		`type "RUBY" is not supported, expected one of: "JAVA", "GO", "NODEJS", "PYTHON2.7", "PYTHON3.4"`,
+		`instances should be a positive integer, got 0`,
+		`service instance "missing" used in bindings does not exist`,
+	)
+}
+
+func TestThatReadApplicationManifest_detectsYAMLByContent(t *testing.T) {
+	path, cleanup := writeTestFile(t, "manifest", "name: 'my-app'\ntype: GO\ninstances: 1\nmetadata:\n  - key: ENV\n    value: prod\n")
+	defer cleanup()
+
+	appManifest, err := ReadApplicationManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := appManifest.Validate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "my-app" || manifest.Instances != 1 || manifest.Metadata[0].Id != "ENV" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestThatReadApplicationManifest_reportsYAMLSyntaxErrorLine(t *testing.T) {
+	path, cleanup := writeTestFile(t, "manifest.yaml", "name: app\ntype: GO\n  instances: 1\n")
+	defer cleanup()
+
+	_, err := ReadApplicationManifest(path)
+
+	expectProblems(t, err, "3: mapping values are not allowed in this context")
+}
+
+func TestThatValidate_reportsMismatchedTypeInYAMLManifest(t *testing.T) {
+	path, cleanup := writeTestFile(t, "manifest.yaml", "name: app\ntype: GO\ninstances: many\n")
+	defer cleanup()
+
+	appManifest, err := ReadApplicationManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = appManifest.Validate(nil)
+
+	expectProblems(t, err, "cannot unmarshal string into instances of type number", "instances should be a positive integer, got 0")
+}
+
+func TestThatValidate_acceptsExampleManifest(t *testing.T) {
+	appManifest, err := ReadApplicationManifest("../../examples/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = appManifest.Validate(nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,176 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var yamlErrorRegexp = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// document is content of JSON or YAML file. YAML is converted to JSON, so that documents
+// of both formats are decoded by encoding/json into the same structs.
+type document struct {
+	file string
+	data []byte
+	// fromYAML tells that offsets in data are not offsets in file
+	fromYAML bool
+}
+
+// readDocument reads JSON or YAML file. Format is detected by extension (.json, .yaml, .yml)
+// and, for other files, by content.
+func readDocument(path string) (*document, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isYAML(path, data) {
+		return &document{file: path, data: data}, nil
+	}
+
+	var content interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, &ValidationError{File: path, Problems: []*Problem{yamlProblem(err)}}
+	}
+	if data, err = json.Marshal(jsonCompatible(content)); err != nil {
+		return nil, err
+	}
+	return &document{file: path, data: data, fromYAML: true}, nil
+}
+
+func isYAML(path string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return false
+	case ".yaml", ".yml":
+		return true
+	}
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[')
+}
+
+// yamlProblem converts decoder error, which refers to lines counted from 0 (and omits line 0)
+func yamlProblem(err error) *Problem {
+	matches := yamlErrorRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return &Problem{Line: 1, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	line, _ := strconv.Atoi(matches[1])
+	return &Problem{Line: line + 1, Message: matches[2]}
+}
+
+// jsonCompatible converts mappings decoded from YAML, which may have keys of any type, to JSON objects
+func jsonCompatible(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, item := range typed {
+			object[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = jsonCompatible(item)
+		}
+	}
+	return value
+}
+
+// decodeStrict unmarshals document into value. Syntax error is returned, mismatched types
+// and keys which do not match any field of value are added to found.
+func (d *document) decodeStrict(value interface{}, found *problems) error {
+	decoder := json.NewDecoder(bytes.NewReader(d.data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(value)
+	if err == nil && decoder.More() {
+		return d.problemAt(decoder.InputOffset()+1, "unexpected data after end of document")
+	}
+	return d.problemsOf(err, found)
+}
+
+// problemsOf converts error of decoding document. Syntax error is returned, mismatched type is added to found.
+func (d *document) problemsOf(err error, found *problems) error {
+	switch typed := err.(type) {
+	case nil:
+		return nil
+	case *json.SyntaxError:
+		return d.problemAt(typed.Offset, typed.Error())
+	case *json.UnmarshalTypeError:
+		name := typed.Field
+		if name == "" {
+			name = "document"
+		}
+		// offset is at the end of mismatched value, so that only its line is reported
+		line, _ := d.position(typed.Offset)
+		found.addAt(line, 0, "cannot unmarshal %s into %s of type %s", typed.Value, name, jsonTypeName(typed.Type))
+		return nil
+	}
+	if err == io.EOF {
+		return &ValidationError{File: d.file, Problems: []*Problem{{Message: "document is empty"}}}
+	}
+	if err == io.ErrUnexpectedEOF {
+		return d.problemAt(int64(len(d.data)), "unexpected end of document")
+	}
+	// the only other error of decoder is unknown key
+	found.add("%s", strings.TrimPrefix(err.Error(), "json: "))
+	return nil
+}
+
+func (d *document) problemAt(offset int64, message string) error {
+	line, column := d.position(offset)
+	return &ValidationError{File: d.file, Problems: []*Problem{{Line: line, Column: column, Message: message}}}
+}
+
+// position returns line and column of byte read last before offset, they are 0 for document converted from YAML
+func (d *document) position(offset int64) (int, int) {
+	if d.fromYAML {
+		return 0, 0
+	}
+	if offset > 0 {
+		offset--
+	}
+	read := d.data[:offset]
+	line := bytes.Count(read, []byte("\n")) + 1
+	return line, len(read) - bytes.LastIndexByte(read, '\n')
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	return "number"
+}
@@ -0,0 +1,103 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Problem is single issue found in manifest. Syntax errors and mismatched types are reported
+// with position in file, problems found by validation name the element they refer to.
+type Problem struct {
+	// Line and Column are 0 when position is not known
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error returns message preceded by line and column, when they are known
+func (p *Problem) Error() string {
+	switch {
+	case p.Line == 0:
+		return p.Message
+	case p.Column == 0:
+		return fmt.Sprintf("%d: %s", p.Line, p.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// ValidationError lists all problems found in manifest file
+type ValidationError struct {
+	File     string
+	Problems []*Problem
+}
+
+func (e *ValidationError) Error() string {
+	buffer := bytes.Buffer{}
+	fmt.Fprintf(&buffer, "%s is not valid:", e.File)
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&buffer, "\n  %s", problem.Error())
+	}
+	return buffer.String()
+}
+
+// problems collects issues found while validating manifest
+type problems []*Problem
+
+func (ps *problems) add(format string, args ...interface{}) {
+	ps.addAt(0, 0, format, args...)
+}
+
+func (ps *problems) addAt(line, column int, format string, args ...interface{}) {
+	*ps = append(*ps, &Problem{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (ps problems) toError(file string) error {
+	if len(ps) == 0 {
+		return nil
+	}
+	return &ValidationError{File: file, Problems: ps}
+}
+
+// checkRequired reports empty value of required element
+func checkRequired(value string, name string, found *problems) bool {
+	if strings.TrimSpace(value) == "" {
+		found.add("%s is required", name)
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func joinQuoted(values []string) string {
+	quoted := []string{}
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/trustedanalytics-ng/tap-api-service/models"
+)
+
+// ReadOfferingManifest reads offering definition from JSON or YAML file and checks elements
+// which are required to create offering
+func ReadOfferingManifest(path string) (models.ServiceDeploy, error) {
+	serviceDeploy := models.ServiceDeploy{}
+	doc, err := readDocument(path)
+	if err != nil {
+		return serviceDeploy, err
+	}
+
+	found := problems{}
+	if err = doc.problemsOf(json.Unmarshal(doc.data, &serviceDeploy), &found); err != nil {
+		return serviceDeploy, err
+	}
+	checkServiceDeploy(serviceDeploy, &found)
+	return serviceDeploy, found.toError(path)
+}
+
+func checkServiceDeploy(serviceDeploy models.ServiceDeploy, found *problems) {
+	if serviceDeploy.Template == nil {
+		found.add("template is required")
+	}
+	if len(serviceDeploy.Services) == 0 {
+		found.add("services should not be empty")
+	}
+	for i, service := range serviceDeploy.Services {
+		for j, plan := range service.Plans {
+			name := fmt.Sprintf("services[%d].plans[%d]", i, j)
+			checkRequired(plan.Name, name+".name", found)
+			for k, dependency := range plan.Dependencies {
+				dependencyName := fmt.Sprintf("%s.dependencies[%d]", name, k)
+				checkRequired(dependency.ServiceName, dependencyName+".service_name", found)
+				checkRequired(dependency.PlanName, dependencyName+".plan_name", found)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestThatReadOfferingManifest_readsYAML(t *testing.T) {
+	path, cleanup := writeTestFile(t, "offering.yaml", `broker_name: test-broker
+template:
+  body:
+    - componentType: instance
+      deployments: []
+services:
+  - name: etcd
+    bindable: true
+    plans:
+      - name: single
+        dependencies:
+          - service_name: other
+            plan_name: small
+`)
+	defer cleanup()
+
+	serviceDeploy, err := ReadOfferingManifest(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serviceDeploy.BrokerName != "test-broker" || len(serviceDeploy.Template["body"].([]interface{})) != 1 {
+		t.Errorf("unexpected offering: %+v", serviceDeploy)
+	}
+	service := serviceDeploy.Services[0]
+	if service.Name != "etcd" || !service.Bindable || service.Plans[0].Dependencies[0].PlanName != "small" {
+		t.Errorf("unexpected service: %+v", service)
+	}
+}
+
+func TestThatReadOfferingManifest_reportsMismatchedTypeAndMissingElements(t *testing.T) {
+	path, cleanup := writeTestFile(t, "offering.yml", `broker_name: test-broker
+services:
+  - name: etcd
+    bindable: "yes"
+    plans:
+      - dependencies:
+          - service_name: other
+`)
+	defer cleanup()
+
+	_, err := ReadOfferingManifest(path)
+
+	expectProblems(t, err,
+		`cannot unmarshal string into services.0.bindable of type boolean`,
+		`template is required`,
+		`services[0].plans[0].name is required`,
+		`services[0].plans[0].dependencies[0].plan_name is required`,
+	)
+}
+
+func TestThatReadOfferingManifest_acceptsExampleOfferings(t *testing.T) {
+	paths, err := filepath.Glob("../../examples/co_*.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("cannot find example offerings: %v", err)
+	}
+	for _, path := range paths {
+		if _, err := ReadOfferingManifest(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
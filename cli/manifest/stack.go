@@ -0,0 +1,123 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Stack declares service instances, applications and bindings which should exist on target
+type Stack struct {
+	Services     []StackService     `json:"services,omitempty"`
+	Applications []StackApplication `json:"applications,omitempty"`
+	Bindings     []StackBinding     `json:"bindings,omitempty"`
+}
+
+// StackService is service instance created from offering plan
+type StackService struct {
+	Name     string            `json:"name"`
+	Offering string            `json:"offering"`
+	Plan     string            `json:"plan"`
+	Envs     map[string]string `json:"envs,omitempty"`
+}
+
+// StackApplication is application pushed from directory or archive. Manifest is looked up in
+// application directory when it is not given. Replicas are not managed when they are not given.
+// Envs are added to metadata from manifest of pushed application. Urls, under which application
+// is exposed, are assigned by TAP - they are exported to compare them, apply does not change them.
+type StackApplication struct {
+	Name     string            `json:"name"`
+	Path     string            `json:"path"`
+	Manifest string            `json:"manifest,omitempty"`
+	Replicas *int              `json:"replicas,omitempty"`
+	Envs     map[string]string `json:"envs,omitempty"`
+	Urls     []string          `json:"urls,omitempty"`
+}
+
+// StackBinding binds source instance to destination one, the same way "binding create" does
+type StackBinding struct {
+	Src string `json:"src"`
+	Dst string `json:"dst"`
+}
+
+// ReadStack reads stack from JSON or YAML file and reports all problems found in it at once.
+// Relative paths of applications are resolved against directory of the stack file.
+func ReadStack(path string) (Stack, error) {
+	stack := Stack{}
+	doc, err := readDocument(path)
+	if err != nil {
+		return stack, err
+	}
+
+	found := problems{}
+	if err = doc.decodeStrict(&stack, &found); err != nil {
+		return stack, err
+	}
+	checkStack(stack, &found)
+	if len(found) > 0 {
+		return stack, found.toError(path)
+	}
+
+	base := filepath.Dir(path)
+	for i := range stack.Applications {
+		stack.Applications[i].Path = resolvePath(base, stack.Applications[i].Path)
+		stack.Applications[i].Manifest = resolvePath(base, stack.Applications[i].Manifest)
+	}
+	return stack, nil
+}
+
+func resolvePath(base, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+func checkStack(stack Stack, found *problems) {
+	names := map[string]bool{}
+	for i, service := range stack.Services {
+		name := fmt.Sprintf("services[%d]", i)
+		checkInstanceName(service.Name, name, names, found)
+		checkRequired(service.Offering, name+".offering", found)
+		checkRequired(service.Plan, name+".plan", found)
+	}
+	for i, application := range stack.Applications {
+		name := fmt.Sprintf("applications[%d]", i)
+		checkInstanceName(application.Name, name, names, found)
+		checkRequired(application.Path, name+".path", found)
+		if application.Replicas != nil && *application.Replicas < 0 {
+			found.add("%s.replicas should be a non-negative integer, got %d", name, *application.Replicas)
+		}
+	}
+	for i, binding := range stack.Bindings {
+		name := fmt.Sprintf("bindings[%d]", i)
+		checkRequired(binding.Src, name+".src", found)
+		checkRequired(binding.Dst, name+".dst", found)
+	}
+}
+
+// checkInstanceName reports names used by more than one instance, names of services and applications share namespace
+func checkInstanceName(instanceName, name string, names map[string]bool, found *problems) {
+	if !checkRequired(instanceName, name+".name", found) {
+		return
+	}
+	if names[instanceName] {
+		found.add("%s.name %q is used by other instance", name, instanceName)
+	}
+	names[instanceName] = true
+}
@@ -0,0 +1,104 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestThatReadStack_readsYAMLAndResolvesApplicationPaths(t *testing.T) {
+	path, cleanup := writeTestFile(t, "stack.yaml", `services:
+  - name: db
+    offering: postgresql
+    plan: free
+    envs:
+      MAX_CONNECTIONS: "20"
+applications:
+  - name: web
+    path: web
+    replicas: 2
+    envs:
+      LOG_LEVEL: debug
+    urls:
+      - web.example.com
+  - name: api
+    path: /srv/api.tar.gz
+    manifest: api.json
+bindings:
+  - src: db
+    dst: web
+`)
+	defer cleanup()
+
+	stack, err := ReadStack(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stack.Services) != 1 || stack.Services[0].Envs["MAX_CONNECTIONS"] != "20" {
+		t.Errorf("unexpected services: %+v", stack.Services)
+	}
+	web, api := stack.Applications[0], stack.Applications[1]
+	if web.Path != filepath.Join(filepath.Dir(path), "web") || web.Replicas == nil || *web.Replicas != 2 {
+		t.Errorf("unexpected application: %+v", web)
+	}
+	if web.Envs["LOG_LEVEL"] != "debug" || len(web.Urls) != 1 || web.Urls[0] != "web.example.com" {
+		t.Errorf("unexpected envs or urls of application: %+v", web)
+	}
+	if api.Path != "/srv/api.tar.gz" || api.Manifest != filepath.Join(filepath.Dir(path), "api.json") || api.Replicas != nil {
+		t.Errorf("unexpected application: %+v", api)
+	}
+	if stack.Bindings[0] != (StackBinding{Src: "db", Dst: "web"}) {
+		t.Errorf("unexpected bindings: %+v", stack.Bindings)
+	}
+}
+
+func TestThatReadStack_reportsAllProblems(t *testing.T) {
+	path, cleanup := writeTestFile(t, "stack.yml", `services:
+  - name: db
+    offering: postgresql
+applications:
+  - name: db
+    path: web
+    replicas: -1
+bindings:
+  - src: db
+volumes: []
+`)
+	defer cleanup()
+
+	_, err := ReadStack(path)
+
+	expectProblems(t, err,
+		`unknown field "volumes"`,
+		`services[0].plan is required`,
+		`applications[0].name "db" is used by other instance`,
+		`applications[0].replicas should be a non-negative integer, got -1`,
+		`bindings[0].dst is required`,
+	)
+}
+
+func TestThatReadStack_reportsMismatchedTypeWithPosition(t *testing.T) {
+	path, cleanup := writeTestFile(t, "stack.json", `{"services": [{"name": "db", "offering": "postgresql", "plan": "free",
+  "envs": {"PORT": 5432}}]}`)
+	defer cleanup()
+
+	_, err := ReadStack(path)
+
+	expectProblems(t, err, `2: cannot unmarshal number into services.0.envs.PORT of type string`)
+}
@@ -0,0 +1,43 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"os"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+type Color string
+
+const (
+	ColorRed    Color = "\033[31m"
+	ColorGreen  Color = "\033[32m"
+	ColorYellow Color = "\033[33m"
+	colorReset        = "\033[0m"
+)
+
+// colorsEnabled is true when stdout is terminal and colors are not disabled with NO_COLOR environment variable
+var colorsEnabled = terminal.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == ""
+
+// Colorize wraps text in escape codes of color, text is returned unchanged when colors are disabled
+func Colorize(text string, color Color) string {
+	if !colorsEnabled {
+		return text
+	}
+	return string(color) + text + colorReset
+}
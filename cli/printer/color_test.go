@@ -0,0 +1,33 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import "testing"
+
+func TestThatColorize_wrapsTextWhenColorsEnabled(t *testing.T) {
+	defer func(enabled bool) { colorsEnabled = enabled }(colorsEnabled)
+
+	colorsEnabled = true
+	if colored := Colorize("+ service db", ColorGreen); colored != "\033[32m+ service db\033[0m" {
+		t.Errorf("unexpected colored text: %q", colored)
+	}
+
+	colorsEnabled = false
+	if plain := Colorize("+ service db", ColorGreen); plain != "+ service db" {
+		t.Errorf("text should not be colored: %q", plain)
+	}
+}
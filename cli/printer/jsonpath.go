@@ -0,0 +1,281 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonPathStepType int
+
+const (
+	jsonPathField jsonPathStepType = iota
+	jsonPathIndex
+	jsonPathWildcard
+	jsonPathRecursive
+)
+
+type jsonPathStep struct {
+	stepType jsonPathStepType
+	field    string
+	index    int
+}
+
+type jsonPathSegment struct {
+	literal string
+	steps   []jsonPathStep
+	isPath  bool
+}
+
+// JSONPath is a parsed template in format used by kubectl, e.g. "{.name}: {.urls[*]}".
+// Supported selectors: .field, ['field'], [n], [*], .* and ..field (recursive descent).
+type JSONPath struct {
+	segments []jsonPathSegment
+}
+
+func ParseJSONPath(template string) (*JSONPath, error) {
+	jp := &JSONPath{}
+	rest := template
+	for len(rest) > 0 {
+		start := strings.Index(rest, "{")
+		if start == -1 {
+			jp.segments = append(jp.segments, jsonPathSegment{literal: rest})
+			break
+		}
+		if start > 0 {
+			jp.segments = append(jp.segments, jsonPathSegment{literal: rest[:start]})
+		}
+		end := strings.Index(rest[start:], "}")
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed jsonpath expression in: %q", template)
+		}
+		steps, err := parseJSONPathExpression(rest[start+1 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		jp.segments = append(jp.segments, jsonPathSegment{steps: steps, isPath: true})
+		rest = rest[start+end+1:]
+	}
+	return jp, nil
+}
+
+func parseJSONPathExpression(expression string) ([]jsonPathStep, error) {
+	steps := []jsonPathStep{}
+	rest := strings.TrimPrefix(strings.TrimSpace(expression), "$")
+	for len(rest) > 0 {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := readJSONPathFieldName(rest[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field name after '..' in jsonpath: %q", expression)
+			}
+			steps = append(steps, jsonPathStep{stepType: jsonPathRecursive, field: name})
+			rest = remaining
+		case strings.HasPrefix(rest, ".*"):
+			steps = append(steps, jsonPathStep{stepType: jsonPathWildcard})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			name, remaining := readJSONPathFieldName(rest[1:])
+			if name != "" {
+				steps = append(steps, jsonPathStep{stepType: jsonPathField, field: name})
+			}
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed bracket in jsonpath: %q", expression)
+			}
+			step, err := parseJSONPathBracket(rest[1:end])
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in jsonpath: %q", rest, expression)
+		}
+	}
+	return steps, nil
+}
+
+func readJSONPathFieldName(expression string) (string, string) {
+	end := strings.IndexAny(expression, ".[")
+	if end == -1 {
+		return expression, ""
+	}
+	return expression[:end], expression[end:]
+}
+
+func parseJSONPathBracket(content string) (jsonPathStep, error) {
+	content = strings.TrimSpace(content)
+	if content == "*" {
+		return jsonPathStep{stepType: jsonPathWildcard}, nil
+	}
+	if len(content) > 1 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return jsonPathStep{stepType: jsonPathField, field: content[1 : len(content)-1]}, nil
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return jsonPathStep{}, fmt.Errorf("unsupported jsonpath subscript: [%s]", content)
+	}
+	return jsonPathStep{stepType: jsonPathIndex, index: index}, nil
+}
+
+// Execute evaluates template against JSON representation of instance.
+// Multiple results of single expression are separated with space.
+func (jp *JSONPath) Execute(instance interface{}) (string, error) {
+	data, err := toGenericJSON(instance)
+	if err != nil {
+		return "", err
+	}
+
+	result := ""
+	for _, segment := range jp.segments {
+		if !segment.isPath {
+			result += segment.literal
+			continue
+		}
+		values, err := evaluateJSONPathSteps([]interface{}{data}, segment.steps)
+		if err != nil {
+			return "", err
+		}
+		formatted := []string{}
+		for _, value := range values {
+			text, err := formatJSONPathValue(value)
+			if err != nil {
+				return "", err
+			}
+			formatted = append(formatted, text)
+		}
+		result += strings.Join(formatted, " ")
+	}
+	return result, nil
+}
+
+func evaluateJSONPathSteps(current []interface{}, steps []jsonPathStep) ([]interface{}, error) {
+	for _, step := range steps {
+		next := []interface{}{}
+		for _, value := range current {
+			results, err := evaluateJSONPathStep(value, step)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, results...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func evaluateJSONPathStep(value interface{}, step jsonPathStep) ([]interface{}, error) {
+	switch step.stepType {
+	case jsonPathField:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot select field %q from non-object value", step.field)
+		}
+		fieldValue, exists := object[step.field]
+		if !exists {
+			return nil, fmt.Errorf("%s is not found", step.field)
+		}
+		return []interface{}{fieldValue}, nil
+	case jsonPathIndex:
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot use index %d on non-array value", step.index)
+		}
+		index := step.index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil, fmt.Errorf("array index %d out of bounds", step.index)
+		}
+		return []interface{}{array[index]}, nil
+	case jsonPathWildcard:
+		return childrenOf(value), nil
+	case jsonPathRecursive:
+		return findRecursively(value, step.field), nil
+	}
+	return nil, fmt.Errorf("unsupported jsonpath step")
+}
+
+func childrenOf(value interface{}) []interface{} {
+	switch typed := value.(type) {
+	case []interface{}:
+		return typed
+	case map[string]interface{}:
+		children := []interface{}{}
+		for _, key := range sortedKeys(typed) {
+			children = append(children, typed[key])
+		}
+		return children
+	}
+	return []interface{}{}
+}
+
+func findRecursively(value interface{}, field string) []interface{} {
+	results := []interface{}{}
+	if object, ok := value.(map[string]interface{}); ok {
+		if fieldValue, exists := object[field]; exists {
+			results = append(results, fieldValue)
+		}
+	}
+	for _, child := range childrenOf(value) {
+		results = append(results, findRecursively(child, field)...)
+	}
+	return results
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatJSONPathValue(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		bytes, err := json.Marshal(typed)
+		return string(bytes), err
+	}
+	return fmt.Sprint(value), nil
+}
+
+// toGenericJSON converts instance to maps and slices, so that selectors refer to json field names
+func toGenericJSON(instance interface{}) (interface{}, error) {
+	bytes, err := json.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(bytes)))
+	decoder.UseNumber()
+	var data interface{}
+	err = decoder.Decode(&data)
+	return data, err
+}
@@ -0,0 +1,85 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"testing"
+
+	apiServiceModels "github.com/trustedanalytics-ng/tap-api-service/models"
+	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
+)
+
+var jsonPathTestApplications = []apiServiceModels.ApplicationInstance{
+	{Name: "app1", State: catalogModels.InstanceStateRunning, Urls: []string{"app1.example.com", "app1.example.org"}, Replication: 2},
+	{Name: "app2", State: catalogModels.InstanceStateStopped, Urls: []string{"app2.example.com"}},
+}
+
+func TestThatJSONPath_selectsFields(t *testing.T) {
+	testCases := []struct {
+		template string
+		instance interface{}
+		expected string
+	}{
+		{"{.name}", jsonPathTestApplications[0], "app1"},
+		{"{$.state}", jsonPathTestApplications[0], "RUNNING"},
+		{"{.urls[1]}", jsonPathTestApplications[0], "app1.example.org"},
+		{"{.urls[-1]}", jsonPathTestApplications[0], "app1.example.org"},
+		{"{.urls}", jsonPathTestApplications[0], `["app1.example.com","app1.example.org"]`},
+		{"{.replication}", jsonPathTestApplications[0], "2"},
+		{"{['name']}", jsonPathTestApplications[1], "app2"},
+		{"{[*].name}", jsonPathTestApplications, "app1 app2"},
+		{"{[0].urls[*]}", jsonPathTestApplications, "app1.example.com app1.example.org"},
+		{"{..urls[0]}", jsonPathTestApplications, "app1.example.com app2.example.com"},
+		{"{.name}={.state}", jsonPathTestApplications[1], "app2=STOPPED"},
+		{"names: {[*].name}", jsonPathTestApplications, "names: app1 app2"},
+	}
+
+	for _, tc := range testCases {
+		jsonPath, err := ParseJSONPath(tc.template)
+		if err != nil {
+			t.Errorf("parsing %q failed: %v", tc.template, err)
+			continue
+		}
+		result, err := jsonPath.Execute(tc.instance)
+		if err != nil {
+			t.Errorf("executing %q failed: %v", tc.template, err)
+			continue
+		}
+		if result != tc.expected {
+			t.Errorf("executing %q returned %q, expected %q", tc.template, result, tc.expected)
+		}
+	}
+}
+
+func TestThatJSONPath_reportsErrors(t *testing.T) {
+	for _, template := range []string{"{.name", "{.urls[x]}", "{..}", "{name}"} {
+		if _, err := ParseJSONPath(template); err == nil {
+			t.Errorf("parsing %q should fail", template)
+		}
+	}
+
+	for _, template := range []string{"{.notExisting}", "{.urls[5]}", "{.name.first}"} {
+		jsonPath, err := ParseJSONPath(template)
+		if err != nil {
+			t.Errorf("parsing %q failed: %v", template, err)
+			continue
+		}
+		if _, err = jsonPath.Execute(jsonPathTestApplications[0]); err == nil {
+			t.Errorf("executing %q should fail", template)
+		}
+	}
+}
@@ -17,15 +17,132 @@
 package printer
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"text/template"
 
+	"github.com/ghodss/yaml"
 	"github.com/olekukonko/tablewriter"
 )
 
 const emptyListMsg = "(empty list)"
 
+type OutputFormat string
+
+const (
+	OutputFormatDefault OutputFormat = ""
+	OutputFormatTable   OutputFormat = "table"
+	OutputFormatWide    OutputFormat = "wide"
+	OutputFormatJSON    OutputFormat = "json"
+	OutputFormatYAML    OutputFormat = "yaml"
+	OutputFormatCSV     OutputFormat = "csv"
+
+	OutputFormatJSONPath   OutputFormat = "jsonpath"
+	OutputFormatGoTemplate OutputFormat = "go-template"
+)
+
+var SupportedOutputFormats = []OutputFormat{OutputFormatTable, OutputFormatWide, OutputFormatJSON, OutputFormatYAML, OutputFormatCSV,
+	OutputFormatJSONPath + "=...", OutputFormatGoTemplate + "=..."}
+
+var outputFormat = OutputFormatDefault
+var outputJSONPath *JSONPath
+var outputGoTemplate *template.Template
+
+// SetOutputFormat accepts format name, optionally followed by "=" and format argument
+// (selector for jsonpath, template for go-template)
+func SetOutputFormat(format string) error {
+	name, argument := format, ""
+	if index := strings.Index(format, "="); index != -1 {
+		name, argument = format[:index], format[index+1:]
+	}
+
+	switch OutputFormat(name) {
+	case OutputFormatJSONPath:
+		if argument == "" {
+			return fmt.Errorf("jsonpath output format requires template, e.g. -o jsonpath={.name}")
+		}
+		jsonPath, err := ParseJSONPath(argument)
+		if err != nil {
+			return err
+		}
+		outputFormat, outputJSONPath = OutputFormatJSONPath, jsonPath
+		return nil
+	case OutputFormatGoTemplate:
+		if argument == "" {
+			return fmt.Errorf("go-template output format requires template, e.g. -o go-template={{.name}}")
+		}
+		goTemplate, err := template.New("output").Option("missingkey=error").Parse(argument)
+		if err != nil {
+			return err
+		}
+		outputFormat, outputGoTemplate = OutputFormatGoTemplate, goTemplate
+		return nil
+	}
+
+	for _, supported := range []OutputFormat{OutputFormatDefault, OutputFormatTable, OutputFormatWide, OutputFormatJSON, OutputFormatYAML, OutputFormatCSV} {
+		if OutputFormat(format) == supported {
+			outputFormat = supported
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format: %q, use one of: %s", format, SupportedOutputFormatsString())
+}
+
+func GetOutputFormat() OutputFormat {
+	return outputFormat
+}
+
+func SupportedOutputFormatsString() string {
+	formats := []string{}
+	for _, format := range SupportedOutputFormats {
+		formats = append(formats, string(format))
+	}
+	return strings.Join(formats, ",")
+}
+
+// PrintList renders items in the output format selected with SetOutputFormat.
+// Tabular formats use Printable columns, json and yaml serialize underlying models.
+// Error is returned when jsonpath or go-template cannot be executed on items.
+func PrintList(items []Printable) error {
+	switch outputFormat {
+	case OutputFormatWide:
+		PrintWideTable(items)
+	case OutputFormatJSON:
+		PrintFormattedJSON(items)
+		fmt.Println()
+	case OutputFormatYAML:
+		PrintYAML(items)
+	case OutputFormatCSV:
+		PrintCSV(items)
+	case OutputFormatJSONPath, OutputFormatGoTemplate:
+		return printProjection(items)
+	default:
+		PrintTable(items)
+	}
+	return nil
+}
+
+// PrintObject renders single resource details. Default output is formatted JSON,
+// table formats are used only when explicitly requested and instance is Printable.
+func PrintObject(instance interface{}) error {
+	printable, isPrintable := instance.(Printable)
+	switch {
+	case outputFormat == OutputFormatYAML:
+		PrintYAML(instance)
+	case outputFormat == OutputFormatJSONPath || outputFormat == OutputFormatGoTemplate:
+		return printProjection(instance)
+	case isPrintable && outputFormat != OutputFormatDefault && outputFormat != OutputFormatJSON:
+		return PrintList([]Printable{printable})
+	default:
+		PrintFormattedJSON(instance)
+		fmt.Println()
+	}
+	return nil
+}
+
 func PrintFormattedJSON(instance interface{}) {
 	prettyJSON, err := json.MarshalIndent(instance, "", "    ")
 	if err == nil {
@@ -35,6 +152,51 @@ func PrintFormattedJSON(instance interface{}) {
 	}
 }
 
+func PrintYAML(instance interface{}) {
+	yamlBytes, err := yaml.Marshal(instance)
+	if err == nil {
+		fmt.Print(string(yamlBytes))
+	} else {
+		fmt.Println("Error marshaling YAML")
+	}
+}
+
+func printProjection(instance interface{}) error {
+	var err error
+	if outputFormat == OutputFormatJSONPath {
+		err = PrintJSONPath(outputJSONPath, instance)
+	} else {
+		err = PrintGoTemplate(outputGoTemplate, instance)
+	}
+	if err != nil {
+		return fmt.Errorf("error executing %s: %v", outputFormat, err)
+	}
+	return nil
+}
+
+func PrintJSONPath(jsonPath *JSONPath, instance interface{}) error {
+	result, err := jsonPath.Execute(instance)
+	if err != nil {
+		return err
+	}
+	fmt.Println(result)
+	return nil
+}
+
+// PrintGoTemplate executes template on JSON representation of instance,
+// so that fields are referred by their json names, e.g. {{.name}}
+func PrintGoTemplate(goTemplate *template.Template, instance interface{}) error {
+	data, err := toGenericJSON(instance)
+	if err != nil {
+		return err
+	}
+	if err = goTemplate.Execute(os.Stdout, data); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
 func PrintTable(items []Printable) {
 	rows := [][]string{}
 	if len(items) < 1 {
@@ -48,6 +210,38 @@ func PrintTable(items []Printable) {
 	createAndRenderTable(header, rows)
 }
 
+func PrintWideTable(items []Printable) {
+	if len(items) < 1 {
+		PrintTable(items)
+		return
+	}
+	header, _ := wideColumns(items[0])
+	rows := [][]string{}
+	for _, i := range items {
+		_, row := wideColumns(i)
+		rows = append(rows, row)
+	}
+	createAndRenderTable(header, rows)
+}
+
+func PrintCSV(items []Printable) {
+	writer := csv.NewWriter(os.Stdout)
+	if len(items) > 0 {
+		writer.Write(items[0].Headers())
+	}
+	for _, i := range items {
+		writer.Write(i.StandarizedData())
+	}
+	writer.Flush()
+}
+
+func wideColumns(item Printable) ([]string, []string) {
+	if wide, ok := item.(WidePrintable); ok {
+		return wide.WideHeaders(), wide.WideData()
+	}
+	return item.Headers(), item.StandarizedData()
+}
+
 func createAndRenderTable(header []string, rows [][]string) {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader(header)
@@ -54,6 +54,97 @@ func TestThatPrintTable_printsAllPrintableData(t *testing.T) {
 	}
 }
 
+func TestThatSetOutputFormat_rejectsUnsupportedFormat(t *testing.T) {
+	if err := SetOutputFormat("xml"); err == nil {
+		t.Error("xml output format should not be supported")
+	}
+	if GetOutputFormat() != OutputFormatDefault {
+		t.Error("output format should not change after failed attempt")
+	}
+}
+
+func TestThatPrintList_printsJSON(t *testing.T) {
+	printables := createExamplaryPrintableList()
+	stdout := captureStdoutWithFormat(t, OutputFormatJSON, func() {
+		PrintList(printables)
+	})
+	assertThatContainsCaseInsensitive(t, stdout, `"Value1": "`+values1[0]+`"`)
+	assertThatContainsCaseInsensitive(t, stdout, `"Value2": `+strconv.Itoa(values2[2]))
+}
+
+func TestThatPrintList_printsYAML(t *testing.T) {
+	printables := createExamplaryPrintableList()
+	stdout := captureStdoutWithFormat(t, OutputFormatYAML, func() {
+		PrintList(printables)
+	})
+	assertThatContainsCaseInsensitive(t, stdout, "- Value1: "+values1[0])
+	assertThatContainsCaseInsensitive(t, stdout, "Value2: "+strconv.Itoa(values2[2]))
+}
+
+func TestThatPrintList_printsCSV(t *testing.T) {
+	printables := createExamplaryPrintableList()
+	stdout := captureStdoutWithFormat(t, OutputFormatCSV, func() {
+		PrintList(printables)
+	})
+	lines := strings.Split(stdout, "\n")
+	assertThatContainsCaseInsensitive(t, lines[0], header1+","+header2)
+	assertThatContainsCaseInsensitive(t, lines[1], values1[0]+","+strconv.Itoa(values2[0]))
+}
+
+func TestThatPrintList_printsWideColumns(t *testing.T) {
+	printables := []Printable{widePrintableTestItem{printableTestItem{Value1: values1[0], Value2: values2[0]}}}
+	stdout := captureStdoutWithFormat(t, OutputFormatWide, func() {
+		PrintList(printables)
+	})
+	assertThatContainsCaseInsensitive(t, stdout, wideHeader)
+	assertThatContainsCaseInsensitive(t, stdout, wideValue)
+}
+
+func TestThatPrintList_printsGoTemplate(t *testing.T) {
+	printables := createExamplaryPrintableList()
+	stdout := captureStdoutWithFormat(t, OutputFormatGoTemplate+`={{range .}}{{.Value1}};{{end}}`, func() {
+		PrintList(printables)
+	})
+	assertThatContainsCaseInsensitive(t, stdout, strings.Join(values1[:], ";"))
+}
+
+func TestThatPrintObject_printsJSONPath(t *testing.T) {
+	stdout := captureStdoutWithFormat(t, OutputFormatJSONPath+"={.Value1}", func() {
+		PrintObject(printableTestItem{Value1: values1[1], Value2: values2[1]})
+	})
+	if stdout != values1[1]+"\n" {
+		t.Errorf("unexpected jsonpath output: %q", stdout)
+	}
+}
+
+func TestThatPrintObject_returnsErrorForMissingKey(t *testing.T) {
+	for _, format := range []OutputFormat{OutputFormatJSONPath + "={.missing}", OutputFormatGoTemplate + "={{.missing}}"} {
+		var err error
+		captureStdoutWithFormat(t, format, func() {
+			err = PrintObject(printableTestItem{Value1: values1[1], Value2: values2[1]})
+		})
+		if err == nil {
+			t.Errorf("%s output should fail for missing key", format)
+		}
+	}
+}
+
+func TestThatSetOutputFormat_requiresTemplate(t *testing.T) {
+	for _, format := range []OutputFormat{OutputFormatJSONPath, OutputFormatGoTemplate, OutputFormatGoTemplate + "={{.name"} {
+		if err := SetOutputFormat(string(format)); err == nil {
+			t.Errorf("%s output format should be rejected", format)
+		}
+	}
+}
+
+func captureStdoutWithFormat(t *testing.T, format OutputFormat, f func()) string {
+	if err := SetOutputFormat(string(format)); err != nil {
+		t.Fatal(err)
+	}
+	defer SetOutputFormat(string(OutputFormatDefault))
+	return test.CaptureStdout(f)
+}
+
 func assertThatContainsCaseInsensitive(t *testing.T, txt string, substring string) {
 	if !strings.Contains(strings.ToLower(txt), strings.ToLower(substring)) {
 		t.Log(txt + " does not contain " + substring)
@@ -79,6 +170,20 @@ func (pti printableTestItem) StandarizedData() []string {
 	return []string{pti.Value1, strconv.Itoa(pti.Value2)}
 }
 
+const wideHeader = "wide column header"
+const wideValue = "wide value"
+
+type widePrintableTestItem struct {
+	printableTestItem
+}
+
+func (wpti widePrintableTestItem) WideHeaders() []string {
+	return append(wpti.Headers(), wideHeader)
+}
+func (wpti widePrintableTestItem) WideData() []string {
+	return append(wpti.StandarizedData(), wideValue)
+}
+
 func createExamplaryPrintableList() []Printable {
 	printables := []Printable{}
 	for i := 0; i < 3; i++ {
@@ -86,3 +191,12 @@ func createExamplaryPrintableList() []Printable {
 	}
 	return printables
 }
+
+func TestThatFormatSize_usesBinaryUnits(t *testing.T) {
+	testCases := map[int64]string{0: "0 B", 1023: "1023 B", 1024: "1.0 KiB", 1536: "1.5 KiB", 5 * 1024 * 1024: "5.0 MiB", 3 << 30: "3.0 GiB"}
+	for size, expected := range testCases {
+		if formatted := FormatSize(size); formatted != expected {
+			t.Errorf("FormatSize(%d) returned %q, expected %q", size, formatted, expected)
+		}
+	}
+}
@@ -17,6 +17,8 @@
 package printer
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +27,7 @@ import (
 	userManagement "github.com/trustedanalytics-ng/tap-api-service/user-management-connector"
 	catalogModels "github.com/trustedanalytics-ng/tap-catalog/models"
 	"github.com/trustedanalytics-ng/tap-cli/api"
+	"github.com/trustedanalytics-ng/tap-cli/cli/archiver"
 )
 
 const timeFormatter = "Jan 02 15:04"
@@ -35,6 +38,13 @@ type Printable interface {
 	StandarizedData() []string
 }
 
+// WidePrintable is implemented by Printables which have additional columns for wide output
+type WidePrintable interface {
+	Printable
+	WideHeaders() []string
+	WideData() []string
+}
+
 type PrintableOffering struct {
 	apiServiceModels.Offering
 }
@@ -49,6 +59,12 @@ func (po PrintableOffering) StandarizedData() []string {
 	}
 	return []string{po.Name, strings.Join(planNames, ", "), po.Description, po.State}
 }
+func (po PrintableOffering) WideHeaders() []string {
+	return append(po.Headers(), "id", "provider", "bindable", "tags")
+}
+func (po PrintableOffering) WideData() []string {
+	return append(po.StandarizedData(), po.Id, po.Provider, strconv.FormatBool(po.Bindable), strings.Join(po.Tags, ", "))
+}
 
 type PrintableService struct {
 	apiServiceModels.ServiceInstance
@@ -63,6 +79,15 @@ func (s PrintableService) StandarizedData() []string {
 		s.AuditTrail.LastUpdateBy, formatTime(s.AuditTrail.LastUpdatedOn),
 		getLastMessageMark(s.Metadata)}
 }
+func (s PrintableService) WideHeaders() []string {
+	return []string{"name", "id", "service", "plan", "state", "created by", "create", "updated by", "update", "message"}
+}
+func (s PrintableService) WideData() []string {
+	return []string{s.Name, s.Id, s.ServiceName, s.ServicePlanName, s.State.String(),
+		s.AuditTrail.CreatedBy, formatTime(s.AuditTrail.CreatedOn),
+		s.AuditTrail.LastUpdateBy, formatTime(s.AuditTrail.LastUpdatedOn),
+		getLastMessage(s.Metadata)}
+}
 
 type PrintableApplication struct {
 	apiServiceModels.ApplicationInstance
@@ -78,6 +103,17 @@ func (app PrintableApplication) StandarizedData() []string {
 		app.AuditTrail.LastUpdateBy, formatTime(app.AuditTrail.LastUpdatedOn),
 		getLastMessageMark(app.Metadata)}
 }
+func (app PrintableApplication) WideHeaders() []string {
+	return []string{"name", "id", "image type", "image state", "state", "replication", "running", "memory", "disk", "urls",
+		"created by", "create", "updated by", "update", "message"}
+}
+func (app PrintableApplication) WideData() []string {
+	return []string{app.Name, app.Id, string(app.ImageType), string(app.ImageState), app.State.String(),
+		strconv.Itoa(app.Replication), strconv.Itoa(app.RunningInstances), app.Memory, app.DiskQuota, strings.Join(app.Urls, ","),
+		app.AuditTrail.CreatedBy, formatTime(app.AuditTrail.CreatedOn),
+		app.AuditTrail.LastUpdateBy, formatTime(app.AuditTrail.LastUpdatedOn),
+		getLastMessage(app.Metadata)}
+}
 
 type PrintableRecentlyPushedApplication struct {
 	catalogModels.Application
@@ -103,6 +139,33 @@ func (pc PrintableCredentials) StandarizedData() []string {
 	return []string{pc.Address, pc.Username}
 }
 
+// MarshalJSON omits token data so that structured output does not expose it
+func (pc PrintableCredentials) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"address": pc.Address, "username": pc.Username})
+}
+
+type PrintableTarget struct {
+	Name    string
+	Current bool
+	api.Credentials
+}
+
+func (pt PrintableTarget) Headers() []string {
+	return []string{"current", "name", "api", "username"}
+}
+func (pt PrintableTarget) StandarizedData() []string {
+	current := ""
+	if pt.Current {
+		current = "*"
+	}
+	return []string{current, pt.Name, pt.Address, pt.Username}
+}
+
+// MarshalJSON omits token data so that structured output does not expose it
+func (pt PrintableTarget) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"name": pt.Name, "current": pt.Current, "address": pt.Address, "username": pt.Username})
+}
+
 type PrintableUser struct {
 	userManagement.UaaUser
 }
@@ -113,6 +176,12 @@ func (pu PrintableUser) Headers() []string {
 func (pu PrintableUser) StandarizedData() []string {
 	return []string{pu.Username}
 }
+func (pu PrintableUser) WideHeaders() []string {
+	return []string{"username", "guid"}
+}
+func (pu PrintableUser) WideData() []string {
+	return []string{pu.Username, pu.Guid}
+}
 
 type PrintableInvitation struct {
 	Email string
@@ -125,18 +194,48 @@ func (pi PrintableInvitation) StandarizedData() []string {
 	return []string{pi.Email}
 }
 
+// PrintableResource is binding of instance, Direction tells if it is instance's own binding ("outgoing")
+// or binding of other instance to it ("incoming")
 type PrintableResource struct {
 	apiServiceModels.InstanceBindingsResource
+	Direction string `json:"direction"`
 }
 
 func (pb PrintableResource) Headers() []string {
-	return []string{"binding name", "binding id"}
+	return []string{"binding name", "binding id", "direction"}
 }
 func (pb PrintableResource) StandarizedData() []string {
 	if pb.AppInstanceName != "" {
-		return []string{pb.AppInstanceName, pb.AppGUID}
+		return []string{pb.AppInstanceName, pb.AppGUID, pb.Direction}
+	}
+	return []string{pb.ServiceInstanceName, pb.ServiceInstanceGUID, pb.Direction}
+}
+func (pb PrintableResource) WideHeaders() []string {
+	return append(pb.Headers(), "type")
+}
+func (pb PrintableResource) WideData() []string {
+	if pb.AppInstanceName != "" {
+		return append(pb.StandarizedData(), string(catalogModels.InstanceTypeApplication))
 	}
-	return []string{pb.ServiceInstanceName, pb.ServiceInstanceGUID}
+	return append(pb.StandarizedData(), string(catalogModels.InstanceTypeService))
+}
+
+type PrintableArchiveEntry struct {
+	archiver.ArchiveEntry
+}
+
+func (pe PrintableArchiveEntry) Headers() []string {
+	return []string{"path", "size"}
+}
+func (pe PrintableArchiveEntry) StandarizedData() []string {
+	return []string{pe.Path, FormatSize(pe.Size)}
+}
+func (pe PrintableArchiveEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"path": pe.Path, "size": pe.Size, "mode": pe.Mode.String()})
+}
+
+func getLastMessage(metadata []catalogModels.Metadata) string {
+	return catalogModels.GetValueFromMetadata(metadata, catalogModels.LAST_STATE_CHANGE_REASON)
 }
 
 func getLastMessageMark(metadata []catalogModels.Metadata) string {
@@ -149,3 +248,17 @@ func getLastMessageMark(metadata []catalogModels.Metadata) string {
 func formatTime(t int64) string {
 	return time.Unix(t, 0).Format(timeFormatter)
 }
+
+// FormatSize returns size in bytes in human readable form, e.g. "1.5 MiB"
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	divisor, exponent := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(divisor), "KMGTPE"[exponent])
+}
@@ -0,0 +1,194 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	progressBarWidth           = 30
+	progressBarRefreshInterval = 200 * time.Millisecond
+	progressLineInterval       = 5 * time.Second
+	// clearLine moves cursor to the beginning of line and erases it
+	clearLine = "\r\033[K"
+)
+
+// progressNow is replaced in tests
+var progressNow = time.Now
+
+// UploadProgressBar reports bytes sent, throughput and estimated remaining time of upload.
+// On terminal it is a single line refreshed in place, otherwise (e.g. in CI logs)
+// separate progress line is printed every few seconds.
+type UploadProgressBar struct {
+	out         io.Writer
+	interactive bool
+
+	mutex        sync.Mutex
+	total        int64
+	sent         int64
+	started      time.Time
+	lastRendered time.Time
+	barDisplayed bool
+}
+
+func NewUploadProgressBar() *UploadProgressBar {
+	return newUploadProgressBar(os.Stdout, terminal.IsTerminal(int(os.Stdout.Fd())))
+}
+
+func newUploadProgressBar(out io.Writer, interactive bool) *UploadProgressBar {
+	return &UploadProgressBar{out: out, interactive: interactive, total: -1}
+}
+
+// Start resets progress before upload of total bytes, total is -1 when size of upload is unknown
+func (p *UploadProgressBar) Start(total int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.total = total
+	p.sent = 0
+	p.started = progressNow()
+	p.lastRendered = p.started
+}
+
+func (p *UploadProgressBar) Add(sent int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.sent += int64(sent)
+	interval := progressLineInterval
+	if p.interactive {
+		interval = progressBarRefreshInterval
+	}
+	if now := progressNow(); now.Sub(p.lastRendered) >= interval {
+		p.render(now)
+	}
+}
+
+// Printf prints message without breaking progress bar displayed on terminal
+func (p *UploadProgressBar) Printf(format string, args ...interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	redraw := p.barDisplayed
+	p.clear()
+	fmt.Fprintf(p.out, format, args...)
+	if redraw {
+		p.render(progressNow())
+	}
+}
+
+// Complete prints final progress of successful upload
+func (p *UploadProgressBar) Complete() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.sent == 0 {
+		return
+	}
+	now := progressNow()
+	if p.interactive {
+		p.render(now)
+		fmt.Fprintln(p.out)
+		p.barDisplayed = false
+		return
+	}
+	elapsed := now.Sub(p.started)
+	fmt.Fprintf(p.out, "Upload finished: %s in %s, %s\n", FormatSize(p.sent), formatDuration(elapsed), formatThroughput(p.sent, elapsed))
+}
+
+// Abort ends progress bar displayed on terminal, so that next messages are printed in new line
+func (p *UploadProgressBar) Abort() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.barDisplayed {
+		fmt.Fprintln(p.out)
+		p.barDisplayed = false
+	}
+}
+
+func (p *UploadProgressBar) clear() {
+	if p.barDisplayed {
+		fmt.Fprint(p.out, clearLine)
+		p.barDisplayed = false
+	}
+}
+
+func (p *UploadProgressBar) render(now time.Time) {
+	p.lastRendered = now
+	elapsed := now.Sub(p.started)
+	throughput := formatThroughput(p.sent, elapsed)
+
+	if !p.interactive {
+		if p.total > 0 {
+			fmt.Fprintf(p.out, "Uploaded %s / %s (%d%%), %s, ETA %s\n", FormatSize(p.sent), FormatSize(p.total),
+				p.percent(), throughput, p.eta(elapsed))
+		} else {
+			fmt.Fprintf(p.out, "Uploaded %s, %s\n", FormatSize(p.sent), throughput)
+		}
+		return
+	}
+
+	p.clear()
+	if p.total > 0 {
+		filled := progressBarWidth * p.percent() / 100
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+		fmt.Fprintf(p.out, "Uploading [%s] %3d%%  %s / %s  %s  ETA %s", bar, p.percent(), FormatSize(p.sent),
+			FormatSize(p.total), throughput, p.eta(elapsed))
+	} else {
+		fmt.Fprintf(p.out, "Uploading %s  %s", FormatSize(p.sent), throughput)
+	}
+	p.barDisplayed = true
+}
+
+func (p *UploadProgressBar) percent() int {
+	if p.sent >= p.total {
+		return 100
+	}
+	return int(p.sent * 100 / p.total)
+}
+
+func (p *UploadProgressBar) eta(elapsed time.Duration) string {
+	if p.sent == 0 {
+		return "unknown"
+	}
+	remaining := p.total - p.sent
+	if remaining <= 0 {
+		return formatDuration(0)
+	}
+	return formatDuration(time.Duration(float64(elapsed) * float64(remaining) / float64(p.sent)))
+}
+
+func formatThroughput(sent int64, elapsed time.Duration) string {
+	if elapsed < time.Millisecond {
+		return "- B/s"
+	}
+	return FormatSize(int64(float64(sent)/elapsed.Seconds())) + "/s"
+}
+
+// formatDuration returns duration with precision of seconds, e.g. "1m5s"
+func formatDuration(duration time.Duration) string {
+	return (duration / time.Second * time.Second).String()
+}
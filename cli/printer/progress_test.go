@@ -0,0 +1,95 @@
+/**
+ * Copyright (c) 2016 Intel Corporation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProgressClock returns functions moving fake time forward and restoring real clock
+func fakeProgressClock() (func(time.Duration), func()) {
+	now := time.Unix(1480000000, 0)
+	progressNow = func() time.Time { return now }
+	return func(elapsed time.Duration) { now = now.Add(elapsed) }, func() { progressNow = time.Now }
+}
+
+func TestThatUploadProgressBar_printsLinesWhenNotInteractive(t *testing.T) {
+	advance, restore := fakeProgressClock()
+	defer restore()
+	out := &bytes.Buffer{}
+	progress := newUploadProgressBar(out, false)
+
+	progress.Start(4096)
+	advance(time.Second)
+	progress.Add(1024)
+	advance(progressLineInterval)
+	progress.Add(1024)
+	advance(3 * time.Second)
+	progress.Add(2048)
+	progress.Complete()
+
+	expected := "Uploaded 2.0 KiB / 4.0 KiB (50%), 341 B/s, ETA 6s\n" +
+		"Upload finished: 4.0 KiB in 9s, 455 B/s\n"
+	if out.String() != expected {
+		t.Errorf("printed %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestThatUploadProgressBar_refreshesBarOnTerminal(t *testing.T) {
+	advance, restore := fakeProgressClock()
+	defer restore()
+	out := &bytes.Buffer{}
+	progress := newUploadProgressBar(out, true)
+
+	progress.Start(2048)
+	advance(time.Second)
+	progress.Add(1024)
+	progress.Printf("message\n")
+	advance(time.Second)
+	progress.Add(1024)
+	progress.Complete()
+
+	lines := strings.Split(out.String(), clearLine)
+	expected := []string{
+		"Uploading [===============               ]  50%  1.0 KiB / 2.0 KiB  1.0 KiB/s  ETA 1s",
+		"message\nUploading [===============               ]  50%  1.0 KiB / 2.0 KiB  1.0 KiB/s  ETA 1s",
+		"Uploading [==============================] 100%  2.0 KiB / 2.0 KiB  1.0 KiB/s  ETA 0s",
+		"Uploading [==============================] 100%  2.0 KiB / 2.0 KiB  1.0 KiB/s  ETA 0s\n",
+	}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("printed %q, expected %q", lines, expected)
+	}
+}
+
+func TestThatUploadProgressBar_showsSentBytesOfUnknownTotal(t *testing.T) {
+	advance, restore := fakeProgressClock()
+	defer restore()
+	out := &bytes.Buffer{}
+	progress := newUploadProgressBar(out, true)
+
+	progress.Start(-1)
+	advance(2 * time.Second)
+	progress.Add(4096)
+	progress.Abort()
+
+	if out.String() != "Uploading 4.0 KiB  2.0 KiB/s\n" {
+		t.Errorf("printed %q", out.String())
+	}
+}